@@ -0,0 +1,342 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"strings"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// jsonRPCCodeMethodNotFound is the JSON-RPC 2.0 code for "method not found".
+const jsonRPCCodeMethodNotFound = -32601
+
+type GasEstimateStrategy string
+
+const (
+	// GasEstimateBundle uses eth_estimateGasBundle, only available on custom nodes.
+	GasEstimateBundle GasEstimateStrategy = "eth_estimateGasBundle"
+	// GasEstimateSequential runs eth_estimateGas tx by tx, carrying the state changes of previous txs
+	// through state overrides built from debug_traceCall prestate diffs.
+	GasEstimateSequential GasEstimateStrategy = "sequential_eth_estimateGas"
+	// GasEstimateSimulateV1 uses eth_simulateV1, the result is the gas used which may be lower than
+	// the gas limit a tx needs to succeed.
+	GasEstimateSimulateV1 GasEstimateStrategy = "eth_simulateV1"
+)
+
+// nolint: gochecknoglobals
+var defaultGasEstimateStrategies = []GasEstimateStrategy{
+	GasEstimateBundle, GasEstimateSequential, GasEstimateSimulateV1,
+}
+
+var ErrNoGasEstimateStrategy = errors.New("no gas estimate strategy supported by the node")
+
+// BundleGasEstimate is the gas of each tx of a bundle and the strategy used to estimate it.
+type BundleGasEstimate struct {
+	Strategy GasEstimateStrategy
+	Gas      []uint64
+}
+
+func (e BundleGasEstimate) Total() uint64 {
+	var total uint64
+	for _, gas := range e.Gas {
+		total += gas
+	}
+
+	return total
+}
+
+// BundleGasEstimator estimates the gas of a bundle, trying each strategy in order and falling back
+// to the next one when the node does not support the RPC method of the current one.
+type BundleGasEstimator struct {
+	simulator  *Simulator
+	strategies []GasEstimateStrategy
+}
+
+var _ mev.IGasBundleEstimator = &BundleGasEstimator{}
+
+// NewBundleGasEstimator creates an estimator using strategies in order, all of them by default.
+func NewBundleGasEstimator(c *rpc.Client, strategies ...GasEstimateStrategy) *BundleGasEstimator {
+	if len(strategies) == 0 {
+		strategies = defaultGasEstimateStrategies
+	}
+
+	return &BundleGasEstimator{
+		simulator:  NewSimulator(c),
+		strategies: strategies,
+	}
+}
+
+// EstimateBundleGas estimates the bundle on the latest block.
+func (e *BundleGasEstimator) EstimateBundleGas(
+	ctx context.Context,
+	messages []ethereum.CallMsg,
+	overrides *map[common.Address]gethclient.OverrideAccount,
+) ([]uint64, error) {
+	estimate, err := e.Estimate(ctx, messages, nil, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	return estimate.Gas, nil
+}
+
+// Estimate estimates the bundle on top of blockNumber, nil means latest.
+func (e *BundleGasEstimator) Estimate(
+	ctx context.Context,
+	messages []ethereum.CallMsg,
+	blockNumber *big.Int,
+	overrides *map[common.Address]gethclient.OverrideAccount,
+) (BundleGasEstimate, error) {
+	var errs []error
+	for _, strategy := range e.strategies {
+		var (
+			gas []uint64
+			err error
+		)
+		switch strategy {
+		case GasEstimateBundle:
+			gas, err = e.estimateBundle(ctx, messages, blockNumber, overrides)
+		case GasEstimateSequential:
+			gas, err = e.estimateSequential(ctx, messages, blockNumber, overrides)
+		case GasEstimateSimulateV1:
+			gas, err = e.simulateV1(ctx, messages, blockNumber, overrides)
+		default:
+			return BundleGasEstimate{}, fmt.Errorf("unknown gas estimate strategy: %s", strategy)
+		}
+		if err == nil {
+			return BundleGasEstimate{Strategy: strategy, Gas: gas}, nil
+		}
+		if !isMethodNotSupported(err) {
+			return BundleGasEstimate{}, fmt.Errorf("%s: %w", strategy, err)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", strategy, err))
+	}
+
+	return BundleGasEstimate{}, fmt.Errorf("%w: %w", ErrNoGasEstimateStrategy, errors.Join(errs...))
+}
+
+func (e *BundleGasEstimator) estimateBundle(
+	ctx context.Context,
+	messages []ethereum.CallMsg,
+	blockNumber *big.Int,
+	overrides *map[common.Address]gethclient.OverrideAccount,
+) ([]uint64, error) {
+	txs := make([]any, 0, len(messages))
+	for _, msg := range messages {
+		txs = append(txs, mev.ToCallArg(msg))
+	}
+
+	var result []hexutil.Uint64
+	if err := e.simulator.c.CallContext(ctx, &result, mev.ETHEstimateGasBundleMethod,
+		map[string]any{"transactions": txs}, toBlockNumArg(blockNumber), overrides,
+	); err != nil {
+		return nil, err
+	}
+	if len(result) != len(messages) {
+		return nil, fmt.Errorf("got %d estimates for %d txs", len(result), len(messages))
+	}
+
+	gas := make([]uint64, 0, len(result))
+	for _, g := range result {
+		gas = append(gas, uint64(g))
+	}
+
+	return gas, nil
+}
+
+func (e *BundleGasEstimator) estimateSequential(
+	ctx context.Context,
+	messages []ethereum.CallMsg,
+	blockNumber *big.Int,
+	overrides *map[common.Address]gethclient.OverrideAccount,
+) ([]uint64, error) {
+	state := make(map[common.Address]gethclient.OverrideAccount)
+	if overrides != nil {
+		for addr, account := range *overrides {
+			account.State = maps.Clone(account.State)
+			account.StateDiff = maps.Clone(account.StateDiff)
+			state[addr] = account
+		}
+	}
+
+	gas := make([]uint64, 0, len(messages))
+	for i, msg := range messages {
+		g, err := e.simulator.EstimateGasWithOverrides(ctx, msg, blockNumber, &state)
+		if err != nil {
+			return nil, fmt.Errorf("estimate tx %d: %w", i, err)
+		}
+		gas = append(gas, g)
+
+		if i == len(messages)-1 {
+			break
+		}
+		diff, err := e.traceStateDiff(ctx, msg, blockNumber, state)
+		if err != nil {
+			return nil, fmt.Errorf("trace tx %d: %w", i, err)
+		}
+		applyStateDiff(state, diff)
+	}
+
+	return gas, nil
+}
+
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Code    *hexutil.Bytes              `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+type prestateDiff struct {
+	Pre  map[common.Address]prestateAccount `json:"pre"`
+	Post map[common.Address]prestateAccount `json:"post"`
+}
+
+func (e *BundleGasEstimator) traceStateDiff(
+	ctx context.Context,
+	msg ethereum.CallMsg,
+	blockNumber *big.Int,
+	state map[common.Address]gethclient.OverrideAccount,
+) (prestateDiff, error) {
+	var diff prestateDiff
+	err := e.simulator.c.CallContext(ctx, &diff, "debug_traceCall",
+		mev.ToCallArg(msg), toBlockNumArg(blockNumber), map[string]any{
+			"tracer":         "prestateTracer",
+			"tracerConfig":   map[string]any{"diffMode": true},
+			"stateOverrides": state,
+		})
+
+	return diff, err
+}
+
+// applyStateDiff merges the post state of a prestateTracer diff into the overrides.
+// In diff mode slots set to zero are left out of post and deleted accounts only appear in pre.
+func applyStateDiff(state map[common.Address]gethclient.OverrideAccount, diff prestateDiff) {
+	for addr := range diff.Pre {
+		if _, ok := diff.Post[addr]; ok {
+			continue
+		}
+		// the nonce can not be overridden to zero, a deleted account keeps it
+		state[addr] = gethclient.OverrideAccount{
+			Balance: new(big.Int),
+			Code:    []byte{},
+			State:   make(map[common.Hash]common.Hash),
+		}
+	}
+
+	for addr, post := range diff.Post {
+		account := state[addr]
+		if post.Balance != nil {
+			account.Balance = post.Balance.ToInt()
+		}
+		if post.Nonce != 0 {
+			account.Nonce = post.Nonce
+		}
+		if post.Code != nil {
+			account.Code = *post.Code
+		}
+
+		changed := maps.Clone(post.Storage)
+		for slot := range diff.Pre[addr].Storage {
+			if _, ok := post.Storage[slot]; !ok {
+				if changed == nil {
+					changed = make(map[common.Hash]common.Hash)
+				}
+				changed[slot] = common.Hash{}
+			}
+		}
+		if len(changed) != 0 {
+			// a node rejects State and StateDiff on the same account
+			slots := account.StateDiff
+			if account.State != nil {
+				slots = account.State
+			} else if slots == nil {
+				slots = make(map[common.Hash]common.Hash, len(changed))
+				account.StateDiff = slots
+			}
+			maps.Copy(slots, changed)
+		}
+		state[addr] = account
+	}
+}
+
+type simulateCallResult struct {
+	Status  hexutil.Uint64 `json:"status"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type simulateBlockResult struct {
+	Calls []simulateCallResult `json:"calls"`
+}
+
+func (e *BundleGasEstimator) simulateV1(
+	ctx context.Context,
+	messages []ethereum.CallMsg,
+	blockNumber *big.Int,
+	overrides *map[common.Address]gethclient.OverrideAccount,
+) ([]uint64, error) {
+	calls := make([]any, 0, len(messages))
+	for _, msg := range messages {
+		calls = append(calls, mev.ToCallArg(msg))
+	}
+	blockStateCall := map[string]any{"calls": calls}
+	if overrides != nil {
+		blockStateCall["stateOverrides"] = overrides
+	}
+
+	var result []simulateBlockResult
+	if err := e.simulator.c.CallContext(ctx, &result, "eth_simulateV1", map[string]any{
+		"blockStateCalls": []any{blockStateCall},
+	}, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	if len(result) != 1 || len(result[0].Calls) != len(messages) {
+		return nil, fmt.Errorf("unexpected eth_simulateV1 result for %d txs", len(messages))
+	}
+
+	gas := make([]uint64, 0, len(messages))
+	for i, call := range result[0].Calls {
+		if call.Status != 1 {
+			reason := "reverted"
+			if call.Error != nil {
+				reason = call.Error.Message
+			}
+			return nil, fmt.Errorf("tx %d failed: %s", i, reason)
+		}
+		gas = append(gas, uint64(call.GasUsed))
+	}
+
+	return gas, nil
+}
+
+func isMethodNotSupported(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == jsonRPCCodeMethodNotFound {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	if !strings.Contains(msg, "method") {
+		return false
+	}
+	for _, sub := range []string{"not found", "not available", "not supported", "unsupported"} {
+		if strings.Contains(msg, sub) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,165 @@
+package eth_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/eth"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEthService struct {
+	blocks    []string
+	overrides []json.RawMessage
+	revert    bool
+}
+
+func (s *fakeEthService) EstimateGas(
+	_ json.RawMessage, block string, overrides json.RawMessage,
+) (hexutil.Uint64, error) {
+	if s.revert {
+		return 0, errors.New("execution reverted")
+	}
+	s.blocks = append(s.blocks, block)
+	s.overrides = append(s.overrides, overrides)
+	return hexutil.Uint64(21000 + len(s.blocks)), nil
+}
+
+type fakeSimulateService struct {
+	fakeEthService
+}
+
+func (s *fakeSimulateService) SimulateV1(opts struct {
+	BlockStateCalls []struct {
+		Calls []json.RawMessage `json:"calls"`
+	} `json:"blockStateCalls"`
+}, _ string,
+) ([]map[string]any, error) {
+	calls := make([]map[string]any, 0)
+	for i := range opts.BlockStateCalls[0].Calls {
+		calls = append(calls, map[string]any{"status": "0x1", "gasUsed": hexutil.Uint64(30000 + i)})
+	}
+	return []map[string]any{{"calls": calls}}, nil
+}
+
+type fakeDebugService struct{}
+
+func (fakeDebugService) TraceCall(args map[string]any, _ string, _ json.RawMessage) (map[string]any, error) {
+	return map[string]any{
+		"pre": map[string]any{},
+		"post": map[string]any{
+			args["from"].(string): map[string]any{"nonce": 1},
+			"0x0000000000000000000000000000000000000002": map[string]any{
+				"storage": map[string]string{common.Hash{1}.Hex(): common.Hash{2}.Hex()},
+			},
+		},
+	}, nil
+}
+
+// fakeClearingDebugService traces a tx zeroing slot 3 of 0x02 and destructing 0x03.
+type fakeClearingDebugService struct{}
+
+func (fakeClearingDebugService) TraceCall(_ map[string]any, _ string, _ json.RawMessage) (map[string]any, error) {
+	return map[string]any{
+		"pre": map[string]any{
+			"0x0000000000000000000000000000000000000002": map[string]any{
+				"storage": map[string]string{common.Hash{3}.Hex(): common.Hash{4}.Hex()},
+			},
+			"0x0000000000000000000000000000000000000003": map[string]any{
+				"balance": "0x10",
+				"code":    "0x6000",
+				"storage": map[string]string{common.Hash{5}.Hex(): common.Hash{6}.Hex()},
+			},
+		},
+		"post": map[string]any{
+			"0x0000000000000000000000000000000000000002": map[string]any{},
+		},
+	}, nil
+}
+
+func newFakeNode(t *testing.T, services map[string]any) *rpc.Client {
+	t.Helper()
+	server := rpc.NewServer()
+	for name, service := range services {
+		require.NoError(t, server.RegisterName(name, service))
+	}
+	t.Cleanup(server.Stop)
+
+	return rpc.DialInProc(server)
+}
+
+func TestBundleGasEstimator_Sequential(t *testing.T) {
+	ethService := &fakeEthService{}
+	c := newFakeNode(t, map[string]any{"eth": ethService, "debug": fakeDebugService{}})
+
+	from := common.HexToAddress("0x01")
+	to := common.HexToAddress("0x02")
+	msgs := []ethereum.CallMsg{{From: from, To: &to}, {From: from, To: &to}}
+	estimate, err := eth.NewBundleGasEstimator(c).Estimate(context.Background(), msgs, big.NewInt(100), nil)
+	require.NoError(t, err)
+	require.Equal(t, eth.GasEstimateSequential, estimate.Strategy)
+	require.Equal(t, []uint64{21001, 21002}, estimate.Gas)
+	require.Equal(t, uint64(42003), estimate.Total())
+
+	require.Equal(t, []string{"0x64", "0x64"}, ethService.blocks)
+	// the second tx runs on top of the state changes of the first one
+	require.JSONEq(t, `{}`, string(ethService.overrides[0]))
+	require.JSONEq(t, `{
+		"0x0000000000000000000000000000000000000001": {"nonce": "0x1"},
+		"0x0000000000000000000000000000000000000002": {"stateDiff": {
+			"0x0100000000000000000000000000000000000000000000000000000000000000":
+			"0x0200000000000000000000000000000000000000000000000000000000000000"
+		}}
+	}`, string(ethService.overrides[1]))
+}
+
+func TestBundleGasEstimator_SequentialClearedState(t *testing.T) {
+	ethService := &fakeEthService{}
+	c := newFakeNode(t, map[string]any{"eth": ethService, "debug": fakeClearingDebugService{}})
+
+	to := common.HexToAddress("0x02")
+	msgs := []ethereum.CallMsg{{To: &to}, {To: &to}}
+	_, err := eth.NewBundleGasEstimator(c, eth.GasEstimateSequential).
+		Estimate(context.Background(), msgs, nil, nil)
+	require.NoError(t, err)
+
+	// the second tx reads the slot zeroed and the account destructed by the first one
+	require.JSONEq(t, `{
+		"0x0000000000000000000000000000000000000002": {"stateDiff": {
+			"0x0300000000000000000000000000000000000000000000000000000000000000":
+			"0x0000000000000000000000000000000000000000000000000000000000000000"
+		}},
+		"0x0000000000000000000000000000000000000003": {"balance": "0x0", "code": "0x", "state": {}}
+	}`, string(ethService.overrides[1]))
+}
+
+func TestBundleGasEstimator_SimulateV1(t *testing.T) {
+	// without debug_traceCall the sequential strategy can not carry state for bundles
+	c := newFakeNode(t, map[string]any{"eth": &fakeSimulateService{}})
+
+	to := common.HexToAddress("0x02")
+	msgs := []ethereum.CallMsg{{To: &to}, {To: &to}}
+	estimate, err := eth.NewBundleGasEstimator(c).Estimate(context.Background(), msgs, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, eth.GasEstimateSimulateV1, estimate.Strategy)
+	require.Equal(t, []uint64{30000, 30001}, estimate.Gas)
+
+	_, err = eth.NewBundleGasEstimator(c, eth.GasEstimateBundle).Estimate(context.Background(), msgs, nil, nil)
+	require.ErrorIs(t, err, eth.ErrNoGasEstimateStrategy)
+}
+
+func TestBundleGasEstimator_NoFallbackOnRevert(t *testing.T) {
+	c := newFakeNode(t, map[string]any{"eth": &fakeSimulateService{fakeEthService{revert: true}}})
+
+	to := common.HexToAddress("0x02")
+	_, err := eth.NewBundleGasEstimator(c).EstimateBundleGas(context.Background(), []ethereum.CallMsg{{To: &to}}, nil)
+	require.ErrorContains(t, err, "execution reverted")
+	require.NotErrorIs(t, err, eth.ErrNoGasEstimateStrategy)
+}
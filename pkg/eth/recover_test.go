@@ -83,6 +83,13 @@ func TestGetFrom(t *testing.T) {
 			types.BlobTxType,
 			true,
 		},
+		{
+			"EIP-7702 Set Code Transaction",
+			big.NewInt(1),
+			types.NewPragueSigner(big.NewInt(1)),
+			types.SetCodeTxType,
+			true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -146,6 +153,16 @@ func createTestTransaction(txType byte, chainID *big.Int) *types.Transaction {
 			Gas:     21000,
 			// Additional fields for blob transactions
 		})
+	case types.SetCodeTxType:
+		uint256ChainID, _ := uint256.FromBig(chainID)
+		return types.NewTx(&types.SetCodeTx{
+			ChainID:  uint256ChainID,
+			Nonce:    0,
+			To:       common.Address{},
+			Value:    uint256.NewInt(0),
+			Gas:      21000,
+			AuthList: []types.SetCodeAuthorization{{ChainID: *uint256ChainID}},
+		})
 	default:
 		panic("Unsupported transaction type")
 	}
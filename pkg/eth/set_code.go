@@ -0,0 +1,62 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// nolint: gochecknoglobals
+var (
+	ErrAuthorizationChainID  = errors.New("authorization chain id mismatch")
+	ErrAuthorizationNonce    = errors.New("authorization nonce mismatch")
+	ErrAuthorizationDelegate = errors.New("authorization delegate mismatch")
+)
+
+// GetAuthorities recovers the authority of each authorization of a set-code tx, in order.
+// It returns nil for other tx types.
+func GetAuthorities(tx *types.Transaction) ([]common.Address, error) {
+	auths := tx.SetCodeAuthorizations()
+	if len(auths) == 0 {
+		return nil, nil
+	}
+
+	authorities := make([]common.Address, 0, len(auths))
+	for i, auth := range auths {
+		authority, err := auth.Authority()
+		if err != nil {
+			return nil, fmt.Errorf("recover authority of authorization %d: %w", i, err)
+		}
+		authorities = append(authorities, authority)
+	}
+
+	return authorities, nil
+}
+
+// ValidateAuthorization checks that auth delegates to delegate and can be applied on chainID
+// by an authority whose account nonce is nonce, it returns the recovered authority.
+// When the authority also sends the set-code tx, nonce must account for the tx itself, i.e. tx nonce + 1.
+func ValidateAuthorization(
+	auth types.SetCodeAuthorization, chainID *big.Int, nonce uint64, delegate common.Address,
+) (common.Address, error) {
+	// a zero chain id allows the authorization on every chain
+	if !auth.ChainID.IsZero() && auth.ChainID.ToBig().Cmp(chainID) != 0 {
+		return common.Address{}, fmt.Errorf("%w: expect %s, got %s", ErrAuthorizationChainID, chainID, auth.ChainID.Dec())
+	}
+	if auth.Nonce != nonce {
+		return common.Address{}, fmt.Errorf("%w: expect %d, got %d", ErrAuthorizationNonce, nonce, auth.Nonce)
+	}
+	if auth.Address != delegate {
+		return common.Address{}, fmt.Errorf("%w: expect %s, got %s", ErrAuthorizationDelegate, delegate, auth.Address)
+	}
+
+	authority, err := auth.Authority()
+	if err != nil {
+		return common.Address{}, fmt.Errorf("recover authority: %w", err)
+	}
+
+	return authority, nil
+}
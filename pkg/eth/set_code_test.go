@@ -0,0 +1,64 @@
+package eth_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCodeAuthorizations(t *testing.T) {
+	senderKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	authorityKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	authority := crypto.PubkeyToAddress(authorityKey.PublicKey)
+	delegate := common.HexToAddress("0x63c0c19a282a1b52b07dd5a65b58948a07dae32b")
+
+	auth, err := types.SignSetCode(authorityKey, types.SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: delegate,
+		Nonce:   5,
+	})
+	require.NoError(t, err)
+	tx, err := types.SignNewTx(senderKey, types.NewPragueSigner(big.NewInt(1)), &types.SetCodeTx{
+		ChainID:   uint256.NewInt(1),
+		To:        authority,
+		Gas:       100000,
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(1),
+		Value:     uint256.NewInt(0),
+		AuthList:  []types.SetCodeAuthorization{auth},
+	})
+	require.NoError(t, err)
+
+	from, err := eth.GetFrom(tx)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(senderKey.PublicKey), from)
+
+	authorities, err := eth.GetAuthorities(tx)
+	require.NoError(t, err)
+	require.Equal(t, []common.Address{authority}, authorities)
+
+	got, err := eth.ValidateAuthorization(auth, big.NewInt(1), 5, delegate)
+	require.NoError(t, err)
+	require.Equal(t, authority, got)
+
+	_, err = eth.ValidateAuthorization(auth, big.NewInt(8453), 5, delegate)
+	require.ErrorIs(t, err, eth.ErrAuthorizationChainID)
+	_, err = eth.ValidateAuthorization(auth, big.NewInt(1), 6, delegate)
+	require.ErrorIs(t, err, eth.ErrAuthorizationNonce)
+	_, err = eth.ValidateAuthorization(auth, big.NewInt(1), 5, common.Address{})
+	require.ErrorIs(t, err, eth.ErrAuthorizationDelegate)
+
+	// chain id 0 is valid on every chain
+	anyChain, err := types.SignSetCode(authorityKey, types.SetCodeAuthorization{Address: delegate, Nonce: 5})
+	require.NoError(t, err)
+	_, err = eth.ValidateAuthorization(anyChain, big.NewInt(8453), 5, delegate)
+	require.NoError(t, err)
+}
@@ -0,0 +1,290 @@
+package eth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/KyberNetwork/tradinglib/pkg/basefee"
+	"github.com/KyberNetwork/tradinglib/pkg/chains"
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/holiman/uint256"
+)
+
+const (
+	defaultGasMarginPercent   = 20
+	defaultBaseFeeHeadroom    = 2
+	baseFeeMaxChangeNumerator = 9 // base fee rises at most 1/8 per block
+	baseFeeMaxChangeDivisor   = 8
+)
+
+// nolint: gochecknoglobals
+var (
+	ErrBlobFeeCapRequired = errors.New("blob fee cap is required on this chain")
+	ErrTxCallRecipient    = errors.New("blob and set code txs require a recipient")
+	ErrTxCallType         = errors.New("tx call can not carry both blobs and authorizations")
+)
+
+// TipStrategy decides the priority fee per gas of the txs built on top of head.
+type TipStrategy interface {
+	GasTipCap(ctx context.Context, head *types.Header) (*big.Int, error)
+}
+
+type TipStrategyFunc func(ctx context.Context, head *types.Header) (*big.Int, error)
+
+func (f TipStrategyFunc) GasTipCap(ctx context.Context, head *types.Header) (*big.Int, error) {
+	return f(ctx, head)
+}
+
+// FixedTip always tips tip wei per gas.
+func FixedTip(tip *big.Int) TipStrategy {
+	return TipStrategyFunc(func(context.Context, *types.Header) (*big.Int, error) {
+		return new(big.Int).Set(tip), nil
+	})
+}
+
+// SuggestedTip tips the eth_maxPriorityFeePerGas suggestion of the node.
+func SuggestedTip(c *rpc.Client) TipStrategy {
+	return TipStrategyFunc(func(ctx context.Context, _ *types.Header) (*big.Int, error) {
+		var tip hexutil.Big
+		if err := c.CallContext(ctx, &tip, "eth_maxPriorityFeePerGas"); err != nil {
+			return nil, fmt.Errorf("get max priority fee per gas: %w", err)
+		}
+		return tip.ToInt(), nil
+	})
+}
+
+// TxCall is the call a TxBuilder turns into a signed tx.
+// A call with BlobSidecar builds a BlobTx, one with AuthList a SetCodeTx, otherwise a DynamicFeeTx.
+type TxCall struct {
+	To         *common.Address
+	Data       []byte
+	Value      *big.Int
+	Nonce      uint64
+	AccessList types.AccessList
+	// Gas skips the estimation when set.
+	Gas uint64
+	// TargetBlock is the block the tx is meant for, zero targets the block following head.
+	TargetBlock uint64
+	// Overrides are applied when estimating gas, e.g. the state changes of the previous bundle txs.
+	Overrides *map[common.Address]gethclient.OverrideAccount
+
+	BlobSidecar *types.BlobTxSidecar
+	// BlobFeeCap is derived from head on Ethereum when not set.
+	BlobFeeCap *big.Int
+
+	AuthList []types.SetCodeAuthorization
+}
+
+type TxBuilderOption func(txBuilderOptions) txBuilderOptions
+
+type txBuilderOptions struct {
+	gasMarginPercent uint64
+	baseFeeHeadroom  uint64
+}
+
+// WithGasMargin adds percent of the estimated gas to the gas limit, the default is 20%.
+func WithGasMargin(percent uint64) TxBuilderOption {
+	return func(opt txBuilderOptions) txBuilderOptions {
+		opt.gasMarginPercent = percent
+		return opt
+	}
+}
+
+// WithBaseFeeHeadroom makes the fee cap cover blocks more full blocks after the target block,
+// so the tx stays valid when it is included later. The default is 2 blocks.
+func WithBaseFeeHeadroom(blocks uint64) TxBuilderOption {
+	return func(opt txBuilderOptions) txBuilderOptions {
+		opt.baseFeeHeadroom = blocks
+		return opt
+	}
+}
+
+// TxBuilder builds signed txs ready for SendBundleV2: the gas limit is estimated with a margin,
+// the fee cap follows the predicted base fee and the tip comes from a TipStrategy.
+type TxBuilder struct {
+	simulator *Simulator
+	chainID   chains.ChainID
+	key       *ecdsa.PrivateKey
+	from      common.Address
+	signer    types.Signer
+	tips      TipStrategy
+	opts      txBuilderOptions
+}
+
+func NewTxBuilder(
+	simulator *Simulator, chainID chains.ChainID, key *ecdsa.PrivateKey, tips TipStrategy, opts ...TxBuilderOption,
+) *TxBuilder {
+	options := txBuilderOptions{
+		gasMarginPercent: defaultGasMarginPercent,
+		baseFeeHeadroom:  defaultBaseFeeHeadroom,
+	}
+	for i := range opts {
+		if opts[i] == nil {
+			continue
+		}
+		options = opts[i](options)
+	}
+
+	return &TxBuilder{
+		simulator: simulator,
+		chainID:   chainID,
+		key:       key,
+		from:      crypto.PubkeyToAddress(key.PublicKey),
+		signer:    types.LatestSignerForChainID(big.NewInt(int64(chainID))),
+		tips:      tips,
+		opts:      options,
+	}
+}
+
+// From returns the sender of the built txs.
+func (b *TxBuilder) From() common.Address {
+	return b.from
+}
+
+// Build builds and signs call for the block following head, or call.TargetBlock when set.
+func (b *TxBuilder) Build(ctx context.Context, call TxCall, head *types.Header) (*types.Transaction, error) {
+	if call.BlobSidecar != nil && len(call.AuthList) != 0 {
+		return nil, ErrTxCallType
+	}
+	if (call.BlobSidecar != nil || len(call.AuthList) != 0) && call.To == nil {
+		return nil, ErrTxCallRecipient
+	}
+
+	tip, err := b.tips.GasTipCap(ctx, head)
+	if err != nil {
+		return nil, fmt.Errorf("get gas tip cap: %w", err)
+	}
+	nextBaseFee, err := basefee.CalcNextBaseFee(uint64(b.chainID), head)
+	if err != nil {
+		return nil, fmt.Errorf("calc next base fee of %s: %w", b.chainID, err)
+	}
+	blocks := b.opts.baseFeeHeadroom
+	if call.TargetBlock > head.Number.Uint64()+1 {
+		blocks += call.TargetBlock - head.Number.Uint64() - 1
+	}
+	feeCap := new(big.Int).Add(withHeadroom(nextBaseFee, blocks), tip)
+
+	var blobFeeCap *big.Int
+	if call.BlobSidecar != nil {
+		if blobFeeCap, err = b.blobFeeCap(call, head, blocks); err != nil {
+			return nil, err
+		}
+	}
+
+	gas := call.Gas
+	if gas == 0 {
+		msg := ethereum.CallMsg{
+			From:              b.from,
+			To:                call.To,
+			GasFeeCap:         feeCap,
+			GasTipCap:         tip,
+			Value:             call.Value,
+			Data:              call.Data,
+			AccessList:        call.AccessList,
+			BlobGasFeeCap:     blobFeeCap,
+			AuthorizationList: call.AuthList,
+		}
+		if call.BlobSidecar != nil {
+			msg.BlobHashes = call.BlobSidecar.BlobHashes()
+		}
+		estimated, err := b.simulator.EstimateGasWithOverrides(ctx, msg, head.Number, call.Overrides)
+		if err != nil {
+			return nil, fmt.Errorf("estimate gas: %w", err)
+		}
+		gas = estimated + estimated*b.opts.gasMarginPercent/100
+	}
+
+	value := call.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	var inner types.TxData
+	switch {
+	case call.BlobSidecar != nil:
+		inner = &types.BlobTx{
+			ChainID:    uint256.NewInt(uint64(b.chainID)),
+			Nonce:      call.Nonce,
+			GasTipCap:  uint256.MustFromBig(tip),
+			GasFeeCap:  uint256.MustFromBig(feeCap),
+			Gas:        gas,
+			To:         *call.To,
+			Value:      uint256.MustFromBig(value),
+			Data:       call.Data,
+			AccessList: call.AccessList,
+			BlobFeeCap: uint256.MustFromBig(blobFeeCap),
+			BlobHashes: call.BlobSidecar.BlobHashes(),
+			Sidecar:    call.BlobSidecar,
+		}
+	case len(call.AuthList) != 0:
+		inner = &types.SetCodeTx{
+			ChainID:    uint256.NewInt(uint64(b.chainID)),
+			Nonce:      call.Nonce,
+			GasTipCap:  uint256.MustFromBig(tip),
+			GasFeeCap:  uint256.MustFromBig(feeCap),
+			Gas:        gas,
+			To:         *call.To,
+			Value:      uint256.MustFromBig(value),
+			Data:       call.Data,
+			AccessList: call.AccessList,
+			AuthList:   call.AuthList,
+		}
+	default:
+		inner = &types.DynamicFeeTx{
+			ChainID:    big.NewInt(int64(b.chainID)),
+			Nonce:      call.Nonce,
+			GasTipCap:  tip,
+			GasFeeCap:  feeCap,
+			Gas:        gas,
+			To:         call.To,
+			Value:      value,
+			Data:       call.Data,
+			AccessList: call.AccessList,
+		}
+	}
+
+	tx, err := types.SignNewTx(b.key, b.signer, inner)
+	if err != nil {
+		return nil, fmt.Errorf("sign tx: %w", err)
+	}
+
+	return tx, nil
+}
+
+func (b *TxBuilder) blobFeeCap(call TxCall, head *types.Header, blocks uint64) (*big.Int, error) {
+	if call.BlobFeeCap != nil {
+		return call.BlobFeeCap, nil
+	}
+	if b.chainID != chains.Ethereum {
+		return nil, fmt.Errorf("%w: %s", ErrBlobFeeCapRequired, b.chainID)
+	}
+	blobBaseFee := mev.NextBlobBaseFee(params.MainnetChainConfig, head)
+	if blobBaseFee == nil {
+		return nil, fmt.Errorf("blobs are not enabled after block %s", head.Number)
+	}
+
+	return withHeadroom(blobBaseFee, blocks), nil
+}
+
+// withHeadroom returns the highest fee reachable from fee after blocks full blocks, rounded up.
+func withHeadroom(fee *big.Int, blocks uint64) *big.Int {
+	out := new(big.Int).Set(fee)
+	for range blocks {
+		out.Mul(out, big.NewInt(baseFeeMaxChangeNumerator))
+		out.Add(out, big.NewInt(baseFeeMaxChangeDivisor-1))
+		out.Div(out, big.NewInt(baseFeeMaxChangeDivisor))
+	}
+
+	return out
+}
@@ -0,0 +1,126 @@
+package eth_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/chains"
+	"github.com/KyberNetwork/tradinglib/pkg/eth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTipService struct {
+	fakeEthService
+}
+
+func (s *fakeTipService) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(params.GWei))
+}
+
+func newHead() *types.Header {
+	excessBlobGas, blobGasUsed := uint64(0), uint64(0)
+	return &types.Header{
+		Number:        big.NewInt(22_000_000),
+		Time:          1_750_000_000,
+		GasLimit:      30_000_000,
+		GasUsed:       15_000_000,
+		BaseFee:       big.NewInt(10 * params.GWei),
+		ExcessBlobGas: &excessBlobGas,
+		BlobGasUsed:   &blobGasUsed,
+	}
+}
+
+func TestTxBuilder_DynamicFeeTx(t *testing.T) {
+	c := newFakeNode(t, map[string]any{"eth": &fakeEthService{}})
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	builder := eth.NewTxBuilder(eth.NewSimulator(c), chains.Ethereum, key, eth.FixedTip(big.NewInt(params.GWei)))
+
+	to := common.HexToAddress("0x02")
+	head := newHead()
+	tx, err := builder.Build(context.Background(), eth.TxCall{
+		To:          &to,
+		Data:        []byte{1},
+		Nonce:       3,
+		TargetBlock: head.Number.Uint64() + 2,
+	}, head)
+	require.NoError(t, err)
+
+	require.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+	require.Equal(t, uint64(3), tx.Nonce())
+	// 21001 estimated plus the 20% margin
+	require.Equal(t, uint64(25201), tx.Gas())
+	require.Equal(t, big.NewInt(params.GWei), tx.GasTipCap())
+	// the 10 gwei base fee of the next block grown over 1 target block and 2 headroom blocks, plus the tip
+	require.Equal(t, big.NewInt(15_238_281_250), tx.GasFeeCap())
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), tx)
+	require.NoError(t, err)
+	require.Equal(t, builder.From(), from)
+}
+
+func TestTxBuilder_SetCodeTx(t *testing.T) {
+	c := newFakeNode(t, map[string]any{"eth": &fakeTipService{}})
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	builder := eth.NewTxBuilder(eth.NewSimulator(c), chains.Ethereum, key, eth.SuggestedTip(c),
+		eth.WithGasMargin(0), nil, eth.WithBaseFeeHeadroom(0))
+
+	auth, err := types.SignSetCode(key, types.SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: common.HexToAddress("0x03"),
+		Nonce:   1,
+	})
+	require.NoError(t, err)
+	to := builder.From()
+	tx, err := builder.Build(context.Background(), eth.TxCall{
+		To:       &to,
+		AuthList: []types.SetCodeAuthorization{auth},
+	}, newHead())
+	require.NoError(t, err)
+	require.Equal(t, uint8(types.SetCodeTxType), tx.Type())
+	require.Equal(t, uint64(21001), tx.Gas())
+	require.Equal(t, big.NewInt(11*params.GWei), tx.GasFeeCap())
+	require.Len(t, tx.SetCodeAuthorizations(), 1)
+
+	_, err = builder.Build(context.Background(), eth.TxCall{AuthList: []types.SetCodeAuthorization{auth}}, newHead())
+	require.ErrorIs(t, err, eth.ErrTxCallRecipient)
+}
+
+func TestTxBuilder_BlobTx(t *testing.T) {
+	c := newFakeNode(t, map[string]any{"eth": &fakeEthService{}})
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	var blob kzg4844.Blob
+	commitment, err := kzg4844.BlobToCommitment(&blob)
+	require.NoError(t, err)
+	proof, err := kzg4844.ComputeBlobProof(&blob, commitment)
+	require.NoError(t, err)
+	sidecar := types.NewBlobTxSidecar(types.BlobSidecarVersion0,
+		[]kzg4844.Blob{blob}, []kzg4844.Commitment{commitment}, []kzg4844.Proof{proof})
+
+	to := common.HexToAddress("0x02")
+	call := eth.TxCall{To: &to, Gas: 50000, BlobSidecar: sidecar}
+	builder := eth.NewTxBuilder(eth.NewSimulator(c), chains.Ethereum, key, eth.FixedTip(big.NewInt(params.GWei)))
+	tx, err := builder.Build(context.Background(), call, newHead())
+	require.NoError(t, err)
+	require.Equal(t, uint8(types.BlobTxType), tx.Type())
+	require.Equal(t, uint64(50000), tx.Gas())
+	require.Equal(t, sidecar.BlobHashes(), tx.BlobHashes())
+	require.NotNil(t, tx.BlobTxSidecar())
+	// the minimum blob base fee of 1 wei grown over the 2 headroom blocks
+	require.Equal(t, big.NewInt(3), tx.BlobGasFeeCap())
+
+	// other chains do not share the blob schedule of Ethereum
+	builder = eth.NewTxBuilder(eth.NewSimulator(c), chains.Polygon, key, eth.FixedTip(big.NewInt(params.GWei)))
+	_, err = builder.Build(context.Background(), call, newHead())
+	require.ErrorIs(t, err, eth.ErrBlobFeeCapRequired)
+}
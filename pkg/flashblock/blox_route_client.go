@@ -194,6 +194,8 @@ func (c *Client) connectAndListen(ctx context.Context, resetRetryDelay func(), s
 		for {
 			select {
 			case <-pingCtx.Done():
+				// unblock ReadMessage when ctx is cancelled
+				_ = conn.Close()
 				return
 			case <-pingTicker.C:
 				if err := conn.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
@@ -214,8 +216,9 @@ func (c *Client) connectAndListen(ctx context.Context, resetRetryDelay func(), s
 		default:
 			_, data, err := conn.ReadMessage()
 			if err != nil {
-				c.l.Errorw("Error reading bloxroute flashblock", "error", err, "data", data)
-				continue
+				c.l.Errorw("Error reading bloxroute flashblock", "error", err)
+				// a failed connection can not be read again, reconnect
+				return fmt.Errorf("stream error: %w", err)
 			}
 
 			messageHandler(ctx, data)
@@ -3,10 +3,16 @@ package flashblock
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
@@ -79,3 +85,41 @@ func TestListenParsedBdnFlashBlock(t *testing.T) {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func TestListenReconnectsAfterReadError(t *testing.T) {
+	// the server drops the connection right after the subscription, the read error must reconnect
+	// instead of reading the failed connection again
+	var subscriptions atomic.Int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		subscriptions.Add(1)
+	}))
+	defer server.Close()
+
+	client, err := NewBloxRouteClient(Config{
+		WebSocketURL: "ws" + strings.TrimPrefix(server.URL, "http"),
+		AuthHeader:   "auth",
+	}, &logPublisher{l: zap.S()})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	err = client.ListenFlashBlock(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if n := subscriptions.Load(); n < 2 {
+		t.Errorf("expected a reconnection, got %d subscriptions", n)
+	}
+}
@@ -0,0 +1,223 @@
+package mempool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/KyberNetwork/tradinglib/pkg/flashblock"
+	"github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+type BloxrouteTxStream string
+
+const (
+	// BloxrouteNewTxs streams transactions as soon as the BDN sees them, before validation.
+	BloxrouteNewTxs BloxrouteTxStream = "newTxs"
+	// BloxroutePendingTxs streams transactions once validated against the node mempool.
+	BloxroutePendingTxs BloxrouteTxStream = "pendingTxs"
+)
+
+// nolint: gochecknoglobals
+var defaultBloxrouteIncludes = []string{"tx_hash", "tx_contents", "raw_tx"}
+
+type BloxrouteTxStreamConfig struct {
+	WebSocketURL string
+	AuthHeader   string
+	// Stream defaults to BloxrouteNewTxs.
+	Stream BloxrouteTxStream
+	// Include lists the fields returned by bloXroute, defaults to tx_hash, tx_contents and raw_tx.
+	Include []string
+	// Filters is a bloXroute filter expression, e.g. "({to} == '0x...') AND ({value} > 1e18)".
+	Filters string
+}
+
+func (c *BloxrouteTxStreamConfig) validate() error {
+	switch c.Stream {
+	case "":
+		c.Stream = BloxrouteNewTxs
+	case BloxrouteNewTxs, BloxroutePendingTxs:
+	default:
+		return fmt.Errorf("invalid stream: %s", c.Stream)
+	}
+	if len(c.Include) == 0 {
+		c.Include = defaultBloxrouteIncludes
+	}
+
+	return nil
+}
+
+// BloxrouteTx is a transaction notification of the newTxs and pendingTxs streams.
+type BloxrouteTx struct {
+	TxHash     common.Hash          `json:"txHash"`
+	RawTx      string               `json:"rawTx,omitempty"`
+	TxContents *BloxrouteTxContents `json:"txContents,omitempty"`
+}
+
+type BloxrouteTxContents struct {
+	From                 *common.Address `json:"from,omitempty"`
+	To                   *common.Address `json:"to,omitempty"`
+	Nonce                *hexutil.Uint64 `json:"nonce,omitempty"`
+	Gas                  *hexutil.Uint64 `json:"gas,omitempty"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Type                 *hexutil.Uint64 `json:"type,omitempty"`
+	Value                *hexutil.Big    `json:"value,omitempty"`
+	Input                hexutil.Bytes   `json:"input,omitempty"`
+}
+
+type bloxrouteTxNotification struct {
+	Params struct {
+		Subscription string       `json:"subscription"`
+		Result       *BloxrouteTx `json:"result"`
+	} `json:"params"`
+}
+
+// BloxrouteTxStreamClient subscribes to the bloXroute pending transaction feed, it reuses the retry logic
+// of flashblock.Client and publishes every transaction as a types.Message with Source BloxRoute.
+type BloxrouteTxStreamClient struct {
+	config    BloxrouteTxStreamConfig
+	ws        *flashblock.Client
+	l         *zap.SugaredLogger
+	publisher MessagePublisher
+}
+
+func NewBloxrouteTxStreamClient(
+	config BloxrouteTxStreamConfig, publisher MessagePublisher,
+) (*BloxrouteTxStreamClient, error) {
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if publisher == nil {
+		return nil, fmt.Errorf("publisher is required")
+	}
+	ws, err := flashblock.NewBloxRouteClient(flashblock.Config{
+		WebSocketURL: config.WebSocketURL,
+		AuthHeader:   config.AuthHeader,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BloxrouteTxStreamClient{
+		config:    config,
+		ws:        ws,
+		l:         zap.S().Named("bloxroute-tx-stream"),
+		publisher: publisher,
+	}, nil
+}
+
+// Listen subscribes to the stream and reconnects until ctx is done.
+func (c *BloxrouteTxStreamClient) Listen(ctx context.Context) error {
+	options := map[string]any{"include": c.config.Include}
+	if c.config.Filters != "" {
+		options["filters"] = c.config.Filters
+	}
+	subscribeMsg := flashblock.WebSocketMessage{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "subscribe",
+		Params:  []any{string(c.config.Stream), options},
+	}
+
+	return c.ws.Listen(ctx, subscribeMsg, c.processTx)
+}
+
+func (c *BloxrouteTxStreamClient) processTx(ctx context.Context, data []byte) {
+	var notification bloxrouteTxNotification
+	if err := json.Unmarshal(data, &notification); err != nil {
+		c.l.Errorw("Error parsing bloxroute tx", "error", err, "data", string(data))
+		return
+	}
+	if notification.Params.Result == nil {
+		// First message is subscription confirmation
+		c.l.Debugw("Received subscription confirmation")
+		return
+	}
+
+	msg, err := BloxrouteTxToMessage(*notification.Params.Result)
+	if err != nil {
+		c.l.Errorw("Error converting bloxroute tx", "error", err, "hash", notification.Params.Result.TxHash)
+		return
+	}
+	if err := c.publisher.PublishMessage(ctx, msg); err != nil {
+		c.l.Errorw("Error publishing bloxroute tx", "error", err, "hash", msg.TxHash)
+	}
+}
+
+// BloxrouteTxToMessage converts a bloXroute transaction into a types.Message with Source BloxRoute.
+// Fields are taken from the raw transaction when included, from the tx contents otherwise.
+func BloxrouteTxToMessage(btx BloxrouteTx) (types.Message, error) {
+	msg := types.Message{
+		TxHash: btx.TxHash.String(),
+		Source: types.BloxRoute,
+	}
+	if contents := btx.TxContents; contents != nil {
+		fillFromContents(&msg, *contents)
+	}
+	if btx.RawTx == "" {
+		return msg, nil
+	}
+
+	rawTx := btx.RawTx
+	if !strings.HasPrefix(rawTx, "0x") {
+		rawTx = "0x" + rawTx
+	}
+	raw, err := hexutil.Decode(rawTx)
+	if err != nil {
+		return types.Message{}, fmt.Errorf("decode raw tx error: %w", err)
+	}
+	var tx gethtypes.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return types.Message{}, fmt.Errorf("unmarshal raw tx error: %w", err)
+	}
+
+	msg.RawTx = rawTx
+	msg.TxHash = tx.Hash().String()
+	msg.Nonce = tx.Nonce()
+	msg.Gas = tx.Gas()
+	msg.GasPrice = tx.GasPrice()
+	msg.GasFeeCap = tx.GasFeeCap()
+	msg.GasTip = tx.GasTipCap()
+	msg.Type = new(big.Int).SetUint64(uint64(tx.Type()))
+	if msg.From == "" {
+		from, err := gethtypes.Sender(gethtypes.LatestSignerForChainID(tx.ChainId()), &tx)
+		if err != nil {
+			return types.Message{}, fmt.Errorf("recover sender error: %w", err)
+		}
+		msg.From = from.String()
+	}
+
+	return msg, nil
+}
+
+func fillFromContents(msg *types.Message, contents BloxrouteTxContents) {
+	if contents.From != nil {
+		msg.From = contents.From.String()
+	}
+	if contents.Nonce != nil {
+		msg.Nonce = uint64(*contents.Nonce)
+	}
+	if contents.Gas != nil {
+		msg.Gas = uint64(*contents.Gas)
+	}
+	if contents.GasPrice != nil {
+		msg.GasPrice = contents.GasPrice.ToInt()
+	}
+	if contents.MaxFeePerGas != nil {
+		msg.GasFeeCap = contents.MaxFeePerGas.ToInt()
+	}
+	if contents.MaxPriorityFeePerGas != nil {
+		msg.GasTip = contents.MaxPriorityFeePerGas.ToInt()
+	}
+	if contents.Type != nil {
+		msg.Type = new(big.Int).SetUint64(uint64(*contents.Type))
+	}
+}
@@ -0,0 +1,88 @@
+package mempool_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mempool"
+	"github.com/KyberNetwork/tradinglib/pkg/mempool/mempooltest"
+	"github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloxrouteTxStreamClient(t *testing.T) {
+	server := mempooltest.NewBloxrouteServer()
+	defer server.Close()
+
+	publisher := newChanPublisher()
+	client, err := mempool.NewBloxrouteTxStreamClient(mempool.BloxrouteTxStreamConfig{
+		WebSocketURL: server.URL(),
+		AuthHeader:   "auth",
+		Stream:       mempool.BloxroutePendingTxs,
+		Filters:      "{to} == '0x0000000000000000000000000000000000000001'",
+	}, publisher)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.Listen(ctx) }()
+
+	require.True(t, server.WaitForSubscriptions(1, 2*time.Second))
+	var params []json.RawMessage
+	require.NoError(t, json.Unmarshal(server.Subscriptions()[0], &params))
+	require.JSONEq(t, `"pendingTxs"`, string(params[0]))
+	require.JSONEq(t, `{
+		"include": ["tx_hash", "tx_contents", "raw_tx"],
+		"filters": "{to} == '0x0000000000000000000000000000000000000001'"
+	}`, string(params[1]))
+	require.Equal(t, []string{"auth"}, server.AuthHeaders())
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	tx, err := gethtypes.SignNewTx(key, gethtypes.LatestSignerForChainID(big.NewInt(1)), &gethtypes.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     3,
+		To:        &to,
+		Gas:       21000,
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(5),
+	})
+	require.NoError(t, err)
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	require.NoError(t, server.Publish(mempool.BloxrouteTx{TxHash: tx.Hash(), RawTx: hexutil.Encode(raw)[2:]}))
+	msg := receive(t, publisher.messages)
+	require.Equal(t, types.BloxRoute, msg.Source)
+	require.Equal(t, tx.Hash().String(), msg.TxHash)
+	require.Equal(t, hexutil.Encode(raw), msg.RawTx)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey).String(), msg.From)
+	require.Equal(t, uint64(3), msg.Nonce)
+	require.Equal(t, uint64(21000), msg.Gas)
+	require.Equal(t, big.NewInt(5), msg.GasFeeCap)
+	require.Equal(t, big.NewInt(2), msg.GasTip)
+
+	// the client resubscribes after the connection drops
+	server.DropConnections()
+	require.True(t, server.WaitForSubscriptions(2, 5*time.Second))
+	nonce := hexutil.Uint64(4)
+	require.NoError(t, server.Publish(mempool.BloxrouteTx{
+		TxHash:     common.HexToHash("0x01"),
+		TxContents: &mempool.BloxrouteTxContents{From: &to, Nonce: &nonce},
+	}))
+	msg = receive(t, publisher.messages)
+	require.Equal(t, to.String(), msg.From)
+	require.Equal(t, uint64(4), msg.Nonce)
+	require.Empty(t, msg.RawTx)
+
+	cancel()
+	require.ErrorIs(t, receive(t, done), context.Canceled)
+}
@@ -0,0 +1,33 @@
+// Package mempool streams pending transactions and MEV-Share hints from private and public mempool feeds.
+package mempool
+
+import (
+	"context"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/types"
+)
+
+const (
+	defaultRetryWait    = time.Second
+	defaultMaxRetryWait = 30 * time.Second
+)
+
+type MevShareEventPublisher interface {
+	PublishMevShareEvent(ctx context.Context, event types.FlashbotMevshareEvent) error
+}
+
+type MessagePublisher interface {
+	PublishMessage(ctx context.Context, msg types.Message) error
+}
+
+// MessageAdapter publishes MEV-Share events as types.Message with Source FlashbotMempool.
+type MessageAdapter struct {
+	Publisher MessagePublisher
+}
+
+var _ MevShareEventPublisher = MessageAdapter{}
+
+func (a MessageAdapter) PublishMevShareEvent(ctx context.Context, event types.FlashbotMevshareEvent) error {
+	return a.Publisher.PublishMessage(ctx, MevShareEventToMessage(event))
+}
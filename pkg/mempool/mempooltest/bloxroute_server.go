@@ -0,0 +1,139 @@
+package mempooltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// BloxrouteServer is a fake bloXroute websocket endpoint for the newTxs and pendingTxs streams.
+type BloxrouteServer struct {
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu            sync.Mutex
+	conns         map[*websocket.Conn]struct{}
+	subscriptions []json.RawMessage
+	authHeaders   []string
+}
+
+func NewBloxrouteServer() *BloxrouteServer {
+	s := &BloxrouteServer{
+		conns: make(map[*websocket.Conn]struct{}),
+	}
+	s.server = httptest.NewServer(s)
+
+	return s
+}
+
+// URL returns the ws:// URL of the server.
+func (s *BloxrouteServer) URL() string {
+	return "ws" + strings.TrimPrefix(s.server.URL, "http")
+}
+
+func (s *BloxrouteServer) Close() {
+	s.DropConnections()
+	s.server.Close()
+}
+
+// Publish sends result as a subscription notification to every subscribed client.
+func (s *BloxrouteServer) Publish(result any) error {
+	notification := map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "subscribe",
+		"params": map[string]any{
+			"subscription": "fake-subscription",
+			"result":       result,
+		},
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		if err := conn.WriteJSON(notification); err != nil {
+			return fmt.Errorf("write notification error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DropConnections closes every open connection, clients are expected to reconnect.
+func (s *BloxrouteServer) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		_ = conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+// Subscriptions returns the params of every subscribe request received so far.
+func (s *BloxrouteServer) Subscriptions() []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]json.RawMessage(nil), s.subscriptions...)
+}
+
+// AuthHeaders returns the Authorization header of every connection received so far.
+func (s *BloxrouteServer) AuthHeaders() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.authHeaders...)
+}
+
+// WaitForSubscriptions waits until n subscribe requests have been received.
+func (s *BloxrouteServer) WaitForSubscriptions(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if len(s.Subscriptions()) >= n {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return false
+}
+
+func (s *BloxrouteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var req struct {
+		ID     int             `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := conn.ReadJSON(&req); err != nil || req.Method != "subscribe" {
+		return
+	}
+
+	s.mu.Lock()
+	s.authHeaders = append(s.authHeaders, r.Header.Get("Authorization"))
+	s.subscriptions = append(s.subscriptions, req.Params)
+	err = conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": "fake-subscription"})
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+
+	// drain control frames until the connection is closed
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package mempooltest provides in-process fake mempool feeds for testing mempool stream clients.
+package mempooltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MevShareServer is a fake MEV-Share Server-Sent Events endpoint.
+// Published events are numbered, fresh clients only get new events while clients reconnecting
+// with a Last-Event-ID header get the events they missed.
+type MevShareServer struct {
+	server *httptest.Server
+
+	mu           sync.Mutex
+	events       [][]byte
+	clients      map[chan struct{}]struct{}
+	lastEventIDs []string
+}
+
+func NewMevShareServer() *MevShareServer {
+	s := &MevShareServer{
+		clients: make(map[chan struct{}]struct{}),
+	}
+	s.server = httptest.NewServer(s)
+
+	return s
+}
+
+func (s *MevShareServer) URL() string {
+	return s.server.URL
+}
+
+func (s *MevShareServer) Client() *http.Client {
+	return s.server.Client()
+}
+
+func (s *MevShareServer) Close() {
+	s.DropConnections()
+	s.server.Close()
+}
+
+// Publish sends event, marshalled to JSON, to every connected client.
+func (s *MevShareServer) Publish(event any) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event error: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, data)
+	for ch := range s.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// DropConnections closes every open stream, clients are expected to reconnect.
+func (s *MevShareServer) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.clients {
+		close(ch)
+		delete(s.clients, ch)
+	}
+}
+
+// Connections returns the Last-Event-ID header of every connection received so far, "" for a fresh one.
+func (s *MevShareServer) Connections() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.lastEventIDs...)
+}
+
+// WaitForClients waits until n clients are connected.
+func (s *MevShareServer) WaitForClients(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		connected := len(s.clients)
+		s.mu.Unlock()
+		if connected >= n {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return false
+}
+
+func (s *MevShareServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	next := -1
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID != "" {
+		id, err := strconv.Atoi(lastEventID)
+		if err != nil {
+			http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		next = id + 1
+	}
+
+	notify := make(chan struct{}, 1)
+	s.mu.Lock()
+	// fresh clients only get new events
+	if next < 0 {
+		next = len(s.events)
+	}
+	s.clients[notify] = struct{}{}
+	s.lastEventIDs = append(s.lastEventIDs, lastEventID)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, notify)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		s.mu.Lock()
+		pending := s.events[min(next, len(s.events)):]
+		s.mu.Unlock()
+		for _, data := range pending {
+			_, _ = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", next, data)
+			next++
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case _, open := <-notify:
+			if !open {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package mempool
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/types"
+	"go.uber.org/zap"
+)
+
+const (
+	MevShareStreamURL = "https://mev-share.flashbots.net"
+
+	maxEventSize = 1 << 20
+)
+
+type MevShareStreamConfig struct {
+	URL string
+	// RetryWait is the first reconnect delay, doubled after each failure up to MaxRetryWait.
+	RetryWait    time.Duration
+	MaxRetryWait time.Duration
+}
+
+func (c *MevShareStreamConfig) validate() error {
+	if c.URL == "" {
+		c.URL = MevShareStreamURL
+	}
+	if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+		return fmt.Errorf("invalid URL: %s", c.URL)
+	}
+	if c.RetryWait <= 0 {
+		c.RetryWait = defaultRetryWait
+	}
+	if c.MaxRetryWait < c.RetryWait {
+		c.MaxRetryWait = max(defaultMaxRetryWait, c.RetryWait)
+	}
+
+	return nil
+}
+
+// MevShareStreamClient consumes the MEV-Share Server-Sent Events stream and reconnects with backoff,
+// resuming from the last received event id.
+type MevShareStreamClient struct {
+	config      MevShareStreamConfig
+	c           *http.Client
+	l           *zap.SugaredLogger
+	publisher   MevShareEventPublisher
+	lastEventID string
+}
+
+// NewMevShareStreamClient creates a stream client, c must not have a Timeout as the stream never ends.
+// http.DefaultClient is used when c is nil.
+func NewMevShareStreamClient(
+	c *http.Client, config MevShareStreamConfig, publisher MevShareEventPublisher,
+) (*MevShareStreamClient, error) {
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if publisher == nil {
+		return nil, fmt.Errorf("publisher is required")
+	}
+	if c == nil {
+		c = http.DefaultClient
+	}
+
+	return &MevShareStreamClient{
+		config:    config,
+		c:         c,
+		l:         zap.S().Named("mevshare-stream"),
+		publisher: publisher,
+	}, nil
+}
+
+// Listen consumes the stream until ctx is done.
+func (c *MevShareStreamClient) Listen(ctx context.Context) error {
+	retryWait := c.config.RetryWait
+	resetRetryWait := func() {
+		retryWait = c.config.RetryWait
+	}
+
+	for {
+		err := c.connectAndListen(ctx, resetRetryWait)
+		if ctx.Err() != nil {
+			c.l.Info("Context cancelled, stopping mev-share stream")
+			return ctx.Err()
+		}
+
+		c.l.Warnw("MEV-Share stream error, retrying", "error", err, "retryWait", retryWait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryWait):
+			retryWait *= 2
+			if retryWait > c.config.MaxRetryWait {
+				retryWait = c.config.MaxRetryWait
+			}
+		}
+	}
+}
+
+func (c *MevShareStreamClient) connectAndListen(ctx context.Context, resetRetryDelay func()) error {
+	c.l.Infow("Connecting to mev-share stream", "url", c.config.URL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.URL, nil)
+	if err != nil {
+		return fmt.Errorf("new http request error: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if c.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", c.lastEventID)
+	}
+
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxEventSize))
+		return fmt.Errorf("not OK status, status: [%d], data: [%s]", resp.StatusCode, string(body))
+	}
+
+	c.l.Info("Connected to mev-share stream, listening for events...")
+	resetRetryDelay()
+
+	return c.readEvents(ctx, resp.Body)
+}
+
+// readEvents parses the event stream, see https://html.spec.whatwg.org/multipage/server-sent-events.html.
+func (c *MevShareStreamClient) readEvents(ctx context.Context, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxEventSize)
+
+	var (
+		eventType string
+		data      []string
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) != 0 && (eventType == "" || eventType == "message") {
+				c.processEvent(ctx, strings.Join(data, "\n"))
+			}
+			eventType, data = "", nil
+			continue
+		}
+		// keep-alive comment
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			data = append(data, value)
+		case "event":
+			eventType = value
+		case "id":
+			c.lastEventID = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream error: %w", err)
+	}
+
+	return errors.New("stream closed")
+}
+
+func (c *MevShareStreamClient) processEvent(ctx context.Context, data string) {
+	var event types.FlashbotMevshareEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		c.l.Errorw("Error parsing mev-share event", "error", err, "data", data)
+		return
+	}
+
+	if err := c.publisher.PublishMevShareEvent(ctx, event); err != nil {
+		c.l.Errorw("Error publishing mev-share event", "error", err, "hash", event.Hash)
+	}
+}
+
+// MevShareEventToMessage converts a MEV-Share event into a types.Message with Source FlashbotMempool.
+// Transaction fields are only filled for single transaction events.
+func MevShareEventToMessage(event types.FlashbotMevshareEvent) types.Message {
+	msg := types.Message{
+		TxHash:                event.Hash.String(),
+		Source:                types.FlashbotMempool,
+		FlashbotMevshareEvent: &event,
+		Logs:                  event.Logs,
+	}
+	if event.GasUsed != nil {
+		msg.GasUsed = uint64(*event.GasUsed)
+	}
+	if len(event.Txs) != 1 {
+		return msg
+	}
+
+	hint := event.Txs[0]
+	if hint.From != nil {
+		msg.From = hint.From.String()
+	}
+	if hint.Nonce != nil {
+		msg.Nonce = uint64(*hint.Nonce)
+	}
+	if hint.Gas != nil {
+		msg.Gas = uint64(*hint.Gas)
+	}
+	if hint.MaxFeePerGas != nil {
+		msg.GasFeeCap = hint.MaxFeePerGas.ToInt()
+	}
+	if hint.MaxPriorityFeePerGas != nil {
+		msg.GasTip = hint.MaxPriorityFeePerGas.ToInt()
+	}
+	if hint.Type != nil {
+		msg.Type = new(big.Int).SetUint64(uint64(*hint.Type))
+	}
+
+	return msg
+}
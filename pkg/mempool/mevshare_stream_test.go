@@ -0,0 +1,105 @@
+package mempool_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mempool"
+	"github.com/KyberNetwork/tradinglib/pkg/mempool/mempooltest"
+	"github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+type chanPublisher struct {
+	events   chan types.FlashbotMevshareEvent
+	messages chan types.Message
+}
+
+func newChanPublisher() *chanPublisher {
+	return &chanPublisher{
+		events:   make(chan types.FlashbotMevshareEvent, 16),
+		messages: make(chan types.Message, 16),
+	}
+}
+
+func (p *chanPublisher) PublishMevShareEvent(_ context.Context, event types.FlashbotMevshareEvent) error {
+	p.events <- event
+	return nil
+}
+
+func (p *chanPublisher) PublishMessage(_ context.Context, msg types.Message) error {
+	p.messages <- msg
+	return nil
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+
+	var zero T
+	return zero
+}
+
+func TestMevShareStreamClient_Reconnect(t *testing.T) {
+	server := mempooltest.NewMevShareServer()
+	defer server.Close()
+
+	publisher := newChanPublisher()
+	client, err := mempool.NewMevShareStreamClient(server.Client(), mempool.MevShareStreamConfig{
+		URL:       server.URL(),
+		RetryWait: 10 * time.Millisecond,
+	}, publisher)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- client.Listen(ctx) }()
+
+	require.True(t, server.WaitForClients(1, 2*time.Second))
+	first := types.FlashbotMevshareEvent{Hash: common.HexToHash("0x01")}
+	require.NoError(t, server.Publish(first))
+	require.Equal(t, first.Hash, receive(t, publisher.events).Hash)
+
+	// the event published while disconnected is resumed from Last-Event-ID
+	server.DropConnections()
+	second := types.FlashbotMevshareEvent{Hash: common.HexToHash("0x02")}
+	require.NoError(t, server.Publish(second))
+	require.Equal(t, second.Hash, receive(t, publisher.events).Hash)
+	require.Equal(t, []string{"", "0"}, server.Connections())
+
+	cancel()
+	require.ErrorIs(t, receive(t, done), context.Canceled)
+}
+
+func TestMevShareEventToMessage(t *testing.T) {
+	from := common.HexToAddress("0xabc")
+	nonce := hexutil.Uint64(7)
+	gasUsed := hexutil.Uint64(21000)
+	event := types.FlashbotMevshareEvent{
+		Hash:    common.HexToHash("0x01"),
+		GasUsed: &gasUsed,
+		Logs: []types.SimulatedPrivateMempoolLog{{
+			Address: common.HexToAddress("0xdef"),
+			Topics:  []common.Hash{common.HexToHash("0x02")},
+		}},
+		Txs: []types.FlashbotMevShareTxHint{{From: &from, Nonce: &nonce}},
+	}
+
+	publisher := newChanPublisher()
+	require.NoError(t, mempool.MessageAdapter{Publisher: publisher}.PublishMevShareEvent(context.Background(), event))
+	msg := receive(t, publisher.messages)
+	require.Equal(t, types.FlashbotMempool, msg.Source)
+	require.Equal(t, event.Hash.String(), msg.TxHash)
+	require.Equal(t, from.String(), msg.From)
+	require.Equal(t, uint64(7), msg.Nonce)
+	require.Equal(t, uint64(21000), msg.GasUsed)
+	require.Len(t, msg.GetAllLogs(), 1)
+}
@@ -87,11 +87,6 @@ func (b BackrunPublicClient) SendBackrunBundle(
 		return SendBundleResponse{}, err
 	}
 
-	if len(resp.Error.Messange) != 0 {
-		return SendBundleResponse{}, fmt.Errorf("response error, code: [%d], message: [%s]",
-			resp.Error.Code, resp.Error.Messange)
-	}
-
 	return resp, nil
 }
 
@@ -0,0 +1,113 @@
+package mev
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// SecondsPerSlot is the time between two blocks on Ethereum mainnet.
+const SecondsPerSlot = 12
+
+// builders accepting type-3 transactions with their sidecar in bundles
+// nolint: gochecknoglobals
+var blobBundleSenderTypes = map[BundleSenderType]struct{}{
+	BundleSenderTypeFlashbot:   {},
+	BundleSenderTypeBeaver:     {},
+	BundleSenderTypeTitan:      {},
+	BundleSenderTypeBuilderNet: {},
+	BundleSenderTypeBloxroute:  {},
+}
+
+// SupportsBlobTxs reports whether the builder accepts blob transactions in bundles.
+func SupportsBlobTxs(senderType BundleSenderType) bool {
+	_, ok := blobBundleSenderTypes[senderType]
+	return ok
+}
+
+func hasBlobTx(txs []*types.Transaction) bool {
+	for _, tx := range txs {
+		if tx.Type() == types.BlobTxType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkBlobSupport(senderType BundleSenderType, txs []*types.Transaction) error {
+	if hasBlobTx(txs) && !SupportsBlobTxs(senderType) {
+		return fmt.Errorf("%w: %s", ErrBlobTxNotSupported, senderType)
+	}
+
+	return nil
+}
+
+// validateBlobSidecar checks that a blob tx carries the blobs matching its versioned hashes,
+// a tx without sidecar would be encoded in its canonical form and rejected by the builder.
+func validateBlobSidecar(tx *types.Transaction) error {
+	if tx.Type() != types.BlobTxType {
+		return nil
+	}
+	sidecar := tx.BlobTxSidecar()
+	if sidecar == nil {
+		return fmt.Errorf("%w: %s", ErrMissingBlobSidecar, tx.Hash())
+	}
+	if err := sidecar.ValidateBlobCommitmentHashes(tx.BlobHashes()); err != nil {
+		return fmt.Errorf("invalid sidecar of tx %s: %w", tx.Hash(), err)
+	}
+
+	return nil
+}
+
+// NextBlobBaseFee returns the blob base fee of the block following parent,
+// or nil if blobs are not enabled at that block.
+func NextBlobBaseFee(config *params.ChainConfig, parent *types.Header) *big.Int {
+	number := new(big.Int).Add(parent.Number, big.NewInt(1))
+	headTime := parent.Time + SecondsPerSlot
+	if !config.IsCancun(number, headTime) {
+		return nil
+	}
+
+	excessBlobGas := eip4844.CalcExcessBlobGas(config, parent, headTime)
+	return eip4844.CalcBlobFee(config, &types.Header{
+		Number:        number,
+		Time:          headTime,
+		ExcessBlobGas: &excessBlobGas,
+	})
+}
+
+// ValidateBlobTxs checks the blob txs of a bundle targeting the block following parent:
+// every blob tx must carry its sidecar, pay at least the blob base fee and the bundle must fit in a block.
+func ValidateBlobTxs(config *params.ChainConfig, parent *types.Header, txs ...*types.Transaction) error {
+	if !hasBlobTx(txs) {
+		return nil
+	}
+	blobBaseFee := NextBlobBaseFee(config, parent)
+	if blobBaseFee == nil {
+		return fmt.Errorf("%w: blobs are not enabled at block %d", ErrBlobTxNotSupported, parent.Number.Uint64()+1)
+	}
+
+	var blobs int
+	for _, tx := range txs {
+		if tx.Type() != types.BlobTxType {
+			continue
+		}
+		if err := validateBlobSidecar(tx); err != nil {
+			return err
+		}
+		if tx.BlobGasFeeCap().Cmp(blobBaseFee) < 0 {
+			return fmt.Errorf("%w: tx %s, fee cap %s, blob base fee %s",
+				ErrBlobFeeCapTooLow, tx.Hash(), tx.BlobGasFeeCap(), blobBaseFee)
+		}
+		blobs += len(tx.BlobHashes())
+	}
+	if maxBlobs := eip4844.MaxBlobsPerBlock(config, parent.Time+SecondsPerSlot); blobs > maxBlobs {
+		return fmt.Errorf("%w: %d blobs, max %d", ErrTooManyBlobs, blobs, maxBlobs)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package mev_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func newBlobTx(t *testing.T, blobs int, blobFeeCap uint64) *types.Transaction {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	var blob kzg4844.Blob
+	blob[0] = 1
+	commitment, err := kzg4844.BlobToCommitment(&blob)
+	require.NoError(t, err)
+	proof, err := kzg4844.ComputeBlobProof(&blob, commitment)
+	require.NoError(t, err)
+	sidecar := types.NewBlobTxSidecar(types.BlobSidecarVersion0,
+		make([]kzg4844.Blob, 0, blobs), make([]kzg4844.Commitment, 0, blobs), make([]kzg4844.Proof, 0, blobs))
+	for range blobs {
+		sidecar.Blobs = append(sidecar.Blobs, blob)
+		sidecar.Commitments = append(sidecar.Commitments, commitment)
+		sidecar.Proofs = append(sidecar.Proofs, proof)
+	}
+
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.BlobTx{
+		ChainID:    uint256.NewInt(1),
+		Gas:        21000,
+		GasTipCap:  uint256.NewInt(1),
+		GasFeeCap:  uint256.NewInt(1),
+		BlobFeeCap: uint256.NewInt(blobFeeCap),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	})
+	require.NoError(t, err)
+
+	return tx
+}
+
+func TestSendBundle_BlobTx(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeFlashbot)
+	defer relay.Close()
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+
+	tx := newBlobTx(t, 1, 1)
+	_, err = client.SendBundle(context.Background(), nil, 100, newSignedTx(t, 0), tx)
+	require.NoError(t, err)
+
+	requests := relay.RequestsFor(mev.ETHSendBundleMethod)
+	require.Len(t, requests, 1)
+	var params []mev.SendBundleParams
+	require.NoError(t, json.Unmarshal(requests[0].Params, &params))
+	require.Len(t, params[0].Txs, 2)
+
+	// the blob tx is sent in its network form, with the sidecar
+	var sent types.Transaction
+	require.NoError(t, sent.UnmarshalBinary(hexutil.MustDecode(params[0].Txs[1])))
+	require.Equal(t, tx.Hash(), sent.Hash())
+	require.NotNil(t, sent.BlobTxSidecar())
+	require.Len(t, sent.BlobTxSidecar().Blobs, 1)
+
+	_, err = client.SendBundle(context.Background(), nil, 100, tx.WithoutBlobTxSidecar())
+	require.ErrorIs(t, err, mev.ErrMissingBlobSidecar)
+	require.Len(t, relay.RequestsFor(mev.ETHSendBundleMethod), 1)
+
+	unsupported, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeRsync, false)
+	require.NoError(t, err)
+	_, err = unsupported.SendBundle(context.Background(), nil, 100, tx)
+	require.ErrorIs(t, err, mev.ErrBlobTxNotSupported)
+	require.False(t, mev.SupportsBlobTxs(mev.BundleSenderTypeRsync))
+}
+
+func TestValidateBlobTxs(t *testing.T) {
+	config := params.MainnetChainConfig
+	zero := uint64(0)
+	parent := &types.Header{
+		Number:        big.NewInt(22_500_000),
+		Time:          1_750_000_000, // prague
+		ExcessBlobGas: &zero,
+		BlobGasUsed:   &zero,
+	}
+	require.Equal(t, big.NewInt(1), mev.NextBlobBaseFee(config, parent))
+
+	require.NoError(t, mev.ValidateBlobTxs(config, parent, newSignedTx(t, 0), newBlobTx(t, 2, 1)))
+	require.ErrorIs(t, mev.ValidateBlobTxs(config, parent, newBlobTx(t, 1, 0)), mev.ErrBlobFeeCapTooLow)
+	require.ErrorIs(t, mev.ValidateBlobTxs(config, parent, newBlobTx(t, 10, 1)), mev.ErrTooManyBlobs)
+	require.ErrorIs(t, mev.ValidateBlobTxs(config, parent, newBlobTx(t, 1, 1).WithoutBlobTxSidecar()),
+		mev.ErrMissingBlobSidecar)
+
+	// blobs are not enabled before cancun
+	parent.Time = 1_700_000_000
+	require.Nil(t, mev.NextBlobBaseFee(config, parent))
+	require.ErrorIs(t, mev.ValidateBlobTxs(config, parent, newBlobTx(t, 1, 1)), mev.ErrBlobTxNotSupported)
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -191,22 +192,9 @@ func (s *BloxrouteBackrunmeSender) SendBackrunBundle(
 	httpReq.Header.Set("Authorization", s.authHeader)
 
 	// Send request
-	resp, err := s.httpClient.Do(httpReq)
-	if err != nil {
-		return SendBundleResponse{}, fmt.Errorf("send http request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Parse response
 	var backrunmeResp backrunmeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&backrunmeResp); err != nil {
-		return SendBundleResponse{}, fmt.Errorf("decode response: %w", err)
-	}
-
-	// Check for errors
-	if backrunmeResp.Error != nil && backrunmeResp.Error.Message != "" {
-		return SendBundleResponse{}, fmt.Errorf("backrunme error [%d]: %s",
-			backrunmeResp.Error.Code, backrunmeResp.Error.Message)
+	if err := s.do(httpReq, &backrunmeResp, func() *backrunmeResponseError { return backrunmeResp.Error }); err != nil {
+		return SendBundleResponse{}, err
 	}
 
 	return SendBundleResponse{
@@ -264,27 +252,18 @@ func (s *BloxrouteBackrunmeSender) MevSimulateBundle(
 	httpReq.Header.Set("Authorization", s.authHeader)
 
 	// Send request
-	resp, err := s.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("send simulate http request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Parse response
 	var simResp simulateResponse
-	if err := json.NewDecoder(resp.Body).Decode(&simResp); err != nil {
-		return nil, fmt.Errorf("decode simulate response: %w", err)
-	}
-
-	// Check for errors
-	if simResp.Error != nil && simResp.Error.Message != "" {
-		return nil, fmt.Errorf("simulate error [%d]: %s",
-			simResp.Error.Code, simResp.Error.Message)
+	if err := s.do(httpReq, &simResp, func() *backrunmeResponseError { return simResp.Error }); err != nil {
+		return nil, err
 	}
 
 	// Check simulation status
 	if simResp.Result.Status != "good" {
-		return nil, fmt.Errorf("simulation status not good: %s", simResp.Result.Status)
+		return nil, &BuilderError{
+			Kind:       ErrSimulationReverted,
+			Message:    fmt.Sprintf("simulation status not good: %s", simResp.Result.Status),
+			HTTPStatus: http.StatusOK,
+		}
 	}
 
 	// Return simulation result as bundle hash for consistency
@@ -295,6 +274,34 @@ func (s *BloxrouteBackrunmeSender) MevSimulateBundle(
 	}, nil
 }
 
+// do sends httpReq and decodes the response into out, rpcErr returns the decoded error of out.
+func (s *BloxrouteBackrunmeSender) do(
+	httpReq *http.Request, out any, rpcErr func() *backrunmeResponseError,
+) error {
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return transportError(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newBuilderError(resp.StatusCode, 0, "", respBody)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("decode response: %w, data: [%s]", err, string(respBody))
+	}
+	if e := rpcErr(); e != nil && e.Message != "" {
+		return newBuilderError(resp.StatusCode, e.Code, e.Message, respBody)
+	}
+
+	return nil
+}
+
 func (s *BloxrouteBackrunmeSender) GetSenderType() BundleSenderType {
 	return BundleSenderTypeBloxrouteBackrunme
 }
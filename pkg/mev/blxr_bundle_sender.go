@@ -35,12 +35,19 @@ type BloxrouteClient struct {
 	enabledBuilders []BlxrBuilder
 }
 
+// SimulateBundle simulates txs with blxr_simulate_bundle on top of the latest state.
 func (s *BloxrouteClient) SimulateBundle(
-	_ context.Context,
-	_ uint64,
-	_ ...*types.Transaction,
+	ctx context.Context,
+	blockNumber uint64,
+	txs ...*types.Transaction,
 ) (SendBundleResponse, error) {
-	return SendBundleResponse{}, ErrMethodNotSupport
+	p := new(BLXRSubmitBundleParams).SetBlockNumber(blockNumber).SetTransactions(txs...)
+	p.StateBlockNumber = "latest"
+	if err := p.Err(); err != nil {
+		return SendBundleResponse{}, err
+	}
+
+	return s.doBundleRequest(ctx, BloxrouteSimulationBundleMethod, p)
 }
 
 func (s *BloxrouteClient) EstimateBundleGas(
@@ -95,6 +102,9 @@ func (s *BloxrouteClient) SendBundleV2(
 	req SendBundleV2Request,
 	txs ...*types.Transaction,
 ) (SendBundleResponse, error) {
+	if err := CheckBundleOptions(req, BundleSenderTypeBloxroute); err != nil {
+		return SendBundleResponse{}, err
+	}
 	p := new(BLXRSubmitBundleParams).
 		SetTransactions(txs...)
 
@@ -147,9 +157,18 @@ func (s *BloxrouteClient) sendBundle(
 	}
 
 	p.MEVBuilders = mevBuilders
+
+	return s.doBundleRequest(ctx, BloxrouteSubmitBundleMethod, p)
+}
+
+func (s *BloxrouteClient) doBundleRequest(
+	ctx context.Context,
+	method string,
+	p *BLXRSubmitBundleParams,
+) (SendBundleResponse, error) {
 	req := BLXRSubmitBundleRequest{
 		ID:     strconv.Itoa(SendBundleID),
-		Method: BloxrouteSubmitBundleMethod,
+		Method: method,
 		Params: p,
 	}
 	reqBody, err := json.Marshal(req)
@@ -166,11 +185,6 @@ func (s *BloxrouteClient) sendBundle(
 		return SendBundleResponse{}, err
 	}
 
-	if len(resp.Error.Messange) != 0 {
-		return SendBundleResponse{}, fmt.Errorf("response error, code: [%d], message: [%s]",
-			resp.Error.Code, resp.Error.Messange)
-	}
-
 	return SendBundleResponse(resp), nil
 }
 
@@ -206,6 +220,8 @@ type BLXRSubmitBundleParams struct {
 	RevertingHashes *[]string              `json:"reverting_hashes,omitempty"`
 	UUID            string                 `json:"uuid,omitempty"`
 	MEVBuilders     map[BlxrBuilder]string `json:"mev_builders,omitempty"`
+	// StateBlockNumber is only used by blxr_simulate_bundle
+	StateBlockNumber string `json:"state_block_number,omitempty"`
 
 	Errors []error `json:"-"`
 }
@@ -217,6 +233,10 @@ func (p *BLXRSubmitBundleParams) SetTransactions(txs ...*types.Transaction) *BLX
 
 	transactions := make([]string, 0, len(txs))
 	for _, tx := range txs {
+		if err := validateBundleTx(tx); err != nil {
+			p.Errors = append(p.Errors, err)
+			continue
+		}
 		txBin, err := tx.MarshalBinary()
 		if err != nil {
 			p.Errors = append(p.Errors, err)
@@ -299,8 +319,20 @@ func bloxrouteSignFlashbot(key *ecdsa.PrivateKey, p *BLXRSubmitBundleParams) (st
 	return signature, nil
 }
 
-func (s *BloxrouteClient) GetBundleStats(_ context.Context, _ uint64, _ common.Hash) (GetBundleStatsResponse, error) {
-	return GetBundleStatsResponse{}, fmt.Errorf("method not support")
+// GetBundleStats returns the bloXroute bundle trace as bundle stats, see TraceBundle.
+func (s *BloxrouteClient) GetBundleStats(
+	ctx context.Context, blockNumber uint64, bundleHash common.Hash,
+) (GetBundleStatsResponse, error) {
+	trace, err := s.TraceBundle(ctx, blockNumber, bundleHash)
+	if err != nil {
+		return GetBundleStatsResponse{}, err
+	}
+
+	return GetBundleStatsResponse{
+		Jsonrpc: JSONRPC2,
+		ID:      GetBundleStatsID,
+		Result:  trace.BundleStats(),
+	}, nil
 }
 
 func (s *BloxrouteClient) GetUserStats(
@@ -308,5 +340,5 @@ func (s *BloxrouteClient) GetUserStats(
 	useV2 bool,
 	blockNumber uint64,
 ) (map[string]any, error) {
-	return nil, fmt.Errorf("method not support")
+	return nil, ErrMethodNotSupport
 }
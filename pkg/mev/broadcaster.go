@@ -1,5 +1,17 @@
 package mev
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
 const (
 	EndpointBeaverbuild   = "https://rpc.beaverbuild.org"
 	EndpointBloxroute     = "https://mev.api.blxrbdn.com"
@@ -20,6 +32,12 @@ const (
 	EndpointBobTheBuilder = "https://rpc.bobthebuilder.xyz"
 )
 
+const (
+	// EndpointFlashbotProtect is the Flashbots Protect private RPC, its status API is EndpointFlashbotProtectStatus.
+	EndpointFlashbotProtect       = "https://rpc.flashbots.net"
+	EndpointFlashbotProtectStatus = "https://protect.flashbots.net"
+)
+
 const (
 	BuilderBeaverbuildID string = "builder-beaverbuild"
 	BuilderBlinkID       string = "builder-blink"
@@ -38,3 +56,243 @@ const (
 	BuilderTitanID       string = "builder-titan"
 	BuilderBobTheBuilder string = "builder-bobthebuilder"
 )
+
+// SenderResult is the outcome of one sender in a fan-out round.
+type SenderResult[R any] struct {
+	Response R
+	Err      error
+	Latency  time.Duration
+}
+
+// BroadcastResult is the outcome of one sender in a broadcast round.
+type BroadcastResult = SenderResult[SendBundleResponse]
+
+// BroadcastResults holds the per-sender outcomes of a broadcast round, keyed by SenderName.
+// Senders that did not complete before the round ended are absent.
+type BroadcastResults map[string]BroadcastResult
+
+// Successes returns the number of senders that completed without error.
+func (r BroadcastResults) Successes() int {
+	var n int
+	for _, res := range r {
+		if res.Err == nil {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Err joins the errors of all failed senders, it returns nil if every sender succeeded.
+func (r BroadcastResults) Err() error {
+	return joinSenderErrors(r)
+}
+
+// joinSenderErrors joins the errors of results prefixed by the sender name, in name order.
+func joinSenderErrors[R any](results map[string]SenderResult[R]) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(results)) {
+		if err := results[name].Err; err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+type BroadcasterOption func(broadcasterOptions) broadcasterOptions
+
+type broadcasterOptions struct {
+	minSuccesses     int
+	perSenderTimeout time.Duration
+}
+
+// WithWaitAll makes the broadcaster wait for every sender, this is the default policy.
+func WithWaitAll() BroadcasterOption {
+	return func(opt broadcasterOptions) broadcasterOptions {
+		opt.minSuccesses = 0
+		return opt
+	}
+}
+
+// WithFirstNSuccesses makes the broadcaster return as soon as n senders succeeded,
+// the remaining in-flight requests are cancelled.
+func WithFirstNSuccesses(n int) BroadcasterOption {
+	return func(opt broadcasterOptions) broadcasterOptions {
+		opt.minSuccesses = n
+		return opt
+	}
+}
+
+// WithPerSenderTimeout bounds each sender call by its own timeout,
+// on top of the deadline of the broadcast context.
+func WithPerSenderTimeout(timeout time.Duration) BroadcasterOption {
+	return func(opt broadcasterOptions) broadcasterOptions {
+		opt.perSenderTimeout = timeout
+		return opt
+	}
+}
+
+// Broadcaster fans out bundle requests to multiple builders concurrently.
+// Senders implementing HealthReporter with an open circuit are skipped and reported with ErrCircuitOpen.
+type Broadcaster struct {
+	senders        []IBundleSender
+	backrunSenders []IBackrunSender
+	opts           broadcasterOptions
+}
+
+// NewBroadcaster returns an error if two senders share the same SenderName, because results are keyed by it.
+// Senders of the same type, e.g. the regions of a builder, must be named with NewNamedSender.
+func NewBroadcaster(
+	senders []IBundleSender,
+	backrunSenders []IBackrunSender,
+	opts ...BroadcasterOption,
+) (*Broadcaster, error) {
+	var options broadcasterOptions
+	for i := range opts {
+		if opts[i] == nil {
+			continue
+		}
+		options = opts[i](options)
+	}
+
+	if err := checkSenderNames(senders); err != nil {
+		return nil, err
+	}
+	if err := checkSenderNames(backrunSenders); err != nil {
+		return nil, err
+	}
+
+	return &Broadcaster{
+		senders:        senders,
+		backrunSenders: backrunSenders,
+		opts:           options,
+	}, nil
+}
+
+func (b *Broadcaster) SendBundleV2(
+	ctx context.Context,
+	req SendBundleV2Request,
+	txs ...*types.Transaction,
+) BroadcastResults {
+	return broadcast(ctx, b.opts, b.senders,
+		func(ctx context.Context, s IBundleSender) (SendBundleResponse, error) {
+			return s.SendBundleV2(ctx, req, txs...)
+		})
+}
+
+func (b *Broadcaster) SimulateBundle(
+	ctx context.Context,
+	blockNumber uint64,
+	txs ...*types.Transaction,
+) BroadcastResults {
+	return broadcast(ctx, b.opts, b.senders,
+		func(ctx context.Context, s IBundleSender) (SendBundleResponse, error) {
+			return s.SimulateBundle(ctx, blockNumber, txs...)
+		})
+}
+
+func (b *Broadcaster) CancelBundle(ctx context.Context, bundleUUID string) BroadcastResults {
+	return broadcast(ctx, b.opts, b.senders,
+		func(ctx context.Context, s IBundleSender) (SendBundleResponse, error) {
+			return SendBundleResponse{}, s.CancelBundle(ctx, bundleUUID)
+		})
+}
+
+func (b *Broadcaster) SendBackrunBundle(
+	ctx context.Context,
+	uuid *string,
+	blockNumber uint64,
+	maxBlockNumber uint64,
+	pendingTxHashes []common.Hash,
+	targetBuilders []string,
+	txs ...*types.Transaction,
+) BroadcastResults {
+	return broadcast(ctx, b.opts, b.backrunSenders,
+		func(ctx context.Context, s IBackrunSender) (SendBundleResponse, error) {
+			return s.SendBackrunBundle(ctx, uuid, blockNumber, maxBlockNumber, pendingTxHashes, targetBuilders, txs...)
+		})
+}
+
+type senderTyper interface {
+	GetSenderType() BundleSenderType
+}
+
+func checkSenderNames[S senderTyper](senders []S) error {
+	seen := make(map[string]struct{}, len(senders))
+	for _, s := range senders {
+		name := SenderName(s)
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateSenderName, name)
+		}
+		seen[name] = struct{}{}
+	}
+
+	return nil
+}
+
+type broadcastItem[R any] struct {
+	name   string
+	result SenderResult[R]
+}
+
+func broadcast[S senderTyper, R any](
+	ctx context.Context,
+	opts broadcasterOptions,
+	senders []S,
+	call func(context.Context, S) (R, error),
+) map[string]SenderResult[R] {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// buffered so that senders finishing after an early return do not block
+	itemCh := make(chan broadcastItem[R], len(senders))
+	for _, s := range senders {
+		if h, ok := any(s).(HealthReporter); ok && h.Health().State == CircuitOpen {
+			itemCh <- broadcastItem[R]{
+				name:   SenderName(s),
+				result: SenderResult[R]{Err: ErrCircuitOpen},
+			}
+			continue
+		}
+
+		go func() {
+			callCtx := ctx
+			if opts.perSenderTimeout > 0 {
+				var callCancel context.CancelFunc
+				callCtx, callCancel = context.WithTimeout(ctx, opts.perSenderTimeout)
+				defer callCancel()
+			}
+
+			start := time.Now()
+			resp, err := call(callCtx, s)
+			itemCh <- broadcastItem[R]{
+				name: SenderName(s),
+				result: SenderResult[R]{
+					Response: resp,
+					Err:      err,
+					Latency:  time.Since(start),
+				},
+			}
+		}()
+	}
+
+	results := make(map[string]SenderResult[R], len(senders))
+	var successes int
+	for range senders {
+		select {
+		case <-ctx.Done():
+			return results
+		case item := <-itemCh:
+			results[item.name] = item.result
+			if item.result.Err == nil {
+				successes++
+			}
+			if opts.minSuccesses > 0 && successes >= opts.minSuccesses {
+				return results
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,157 @@
+package mev_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSender struct {
+	senderType mev.BundleSenderType
+	delay      time.Duration
+	err        error
+}
+
+func (s stubSender) wait(ctx context.Context) (mev.SendBundleResponse, error) {
+	select {
+	case <-ctx.Done():
+		return mev.SendBundleResponse{}, ctx.Err()
+	case <-time.After(s.delay):
+	}
+	if s.err != nil {
+		return mev.SendBundleResponse{}, s.err
+	}
+
+	return mev.SendBundleResponse{Result: mev.SendBundleResult{BundleHash: s.senderType.String()}}, nil
+}
+
+func (s stubSender) SendBundle(
+	ctx context.Context, _ *string, _ uint64, _ ...*types.Transaction,
+) (mev.SendBundleResponse, error) {
+	return s.wait(ctx)
+}
+
+func (s stubSender) SendBundleV2(
+	ctx context.Context, _ mev.SendBundleV2Request, _ ...*types.Transaction,
+) (mev.SendBundleResponse, error) {
+	return s.wait(ctx)
+}
+
+func (s stubSender) SendBundleHex(
+	ctx context.Context, _ *string, _ uint64, _ ...string,
+) (mev.SendBundleResponse, error) {
+	return s.wait(ctx)
+}
+
+func (s stubSender) CancelBundle(ctx context.Context, _ string) error {
+	_, err := s.wait(ctx)
+	return err
+}
+
+func (s stubSender) SendPrivateRawTransaction(
+	_ context.Context, _ *types.Transaction,
+) (mev.SendPrivateRawTransactionResponse, error) {
+	return mev.SendPrivateRawTransactionResponse{}, nil
+}
+
+func (s stubSender) SimulateBundle(
+	ctx context.Context, _ uint64, _ ...*types.Transaction,
+) (mev.SendBundleResponse, error) {
+	return s.wait(ctx)
+}
+
+func (s stubSender) GetSenderType() mev.BundleSenderType {
+	return s.senderType
+}
+
+func (s stubSender) GetBundleStats(
+	_ context.Context, _ uint64, _ common.Hash,
+) (mev.GetBundleStatsResponse, error) {
+	return mev.GetBundleStatsResponse{}, mev.ErrMethodNotSupport
+}
+
+func (s stubSender) GetUserStats(_ context.Context, _ bool, _ uint64) (map[string]any, error) {
+	return nil, mev.ErrMethodNotSupport
+}
+
+func TestBroadcaster_WaitAll(t *testing.T) {
+	errBuilder := errors.New("builder down")
+	b, err := mev.NewBroadcaster([]mev.IBundleSender{
+		stubSender{senderType: mev.BundleSenderTypeFlashbot},
+		stubSender{senderType: mev.BundleSenderTypeTitan, delay: 10 * time.Millisecond},
+		stubSender{senderType: mev.BundleSenderTypeBeaver, err: errBuilder},
+	}, nil)
+	require.NoError(t, err)
+
+	results := b.SendBundleV2(context.Background(), mev.SendBundleV2Request{})
+	require.Len(t, results, 3)
+	require.Equal(t, 2, results.Successes())
+	require.Equal(t, mev.BundleSenderTypeTitan.String(), results[mev.BundleSenderTypeTitan.String()].Response.Result.BundleHash)
+	require.GreaterOrEqual(t, results[mev.BundleSenderTypeTitan.String()].Latency, 10*time.Millisecond)
+	require.ErrorIs(t, results.Err(), errBuilder)
+	require.IsType(t, mev.SenderResult[mev.SendBundleResponse]{}, results[mev.BundleSenderTypeFlashbot.String()])
+}
+
+func TestBroadcaster_FirstNSuccesses(t *testing.T) {
+	b, err := mev.NewBroadcaster([]mev.IBundleSender{
+		stubSender{senderType: mev.BundleSenderTypeFlashbot},
+		stubSender{senderType: mev.BundleSenderTypeTitan, delay: time.Second},
+	}, nil, mev.WithFirstNSuccesses(1))
+	require.NoError(t, err)
+
+	start := time.Now()
+	results := b.SimulateBundle(context.Background(), 1)
+	require.Less(t, time.Since(start), time.Second)
+	require.Len(t, results, 1)
+	require.NoError(t, results[mev.BundleSenderTypeFlashbot.String()].Err)
+}
+
+func TestBroadcaster_PerSenderTimeout(t *testing.T) {
+	b, err := mev.NewBroadcaster([]mev.IBundleSender{
+		stubSender{senderType: mev.BundleSenderTypeFlashbot},
+		stubSender{senderType: mev.BundleSenderTypeTitan, delay: time.Second},
+	}, nil, mev.WithPerSenderTimeout(20*time.Millisecond))
+	require.NoError(t, err)
+
+	results := b.CancelBundle(context.Background(), "uuid")
+	require.Len(t, results, 2)
+	require.NoError(t, results[mev.BundleSenderTypeFlashbot.String()].Err)
+	require.ErrorIs(t, results[mev.BundleSenderTypeTitan.String()].Err, context.DeadlineExceeded)
+}
+
+func TestBroadcaster_NamedSenders(t *testing.T) {
+	us := mevtest.NewRelay(mev.BundleSenderTypeTitan)
+	defer us.Close()
+	eu := mevtest.NewRelay(mev.BundleSenderTypeTitan)
+	defer eu.Close()
+	eu.Script(mev.ETHSendBundleMethod, mevtest.Response{HTTPStatus: http.StatusBadGateway})
+
+	newTitan := func(relay *mevtest.Relay) mev.IBundleSender {
+		client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeTitan, false)
+		require.NoError(t, err)
+		return client
+	}
+	_, err := mev.NewBroadcaster([]mev.IBundleSender{newTitan(us), newTitan(eu)}, nil)
+	require.ErrorIs(t, err, mev.ErrDuplicateSenderName)
+
+	b, err := mev.NewBroadcaster([]mev.IBundleSender{
+		mev.NewNamedSender(newTitan(us), "titan-us"),
+		mev.NewNamedSender(newTitan(eu), "titan-eu"),
+	}, nil)
+	require.NoError(t, err)
+
+	results := b.SendBundleV2(context.Background(), mev.SendBundleV2Request{}, newSignedTx(t, 0))
+	require.Len(t, results, 2)
+	require.NoError(t, results["titan-us"].Err)
+	require.ErrorIs(t, results["titan-eu"].Err, mev.ErrBuilderUnavailable)
+	require.Len(t, us.RequestsFor(mev.ETHSendBundleMethod), 1)
+	require.Len(t, eu.RequestsFor(mev.ETHSendBundleMethod), 1)
+}
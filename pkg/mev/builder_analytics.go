@@ -0,0 +1,348 @@
+package mev
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// HeaderReader reads block headers, ChainReader and *ethclient.Client satisfy it.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// BuilderSignature identifies the blocks of a builder by fee recipient or by extraData.
+type BuilderSignature struct {
+	// BuilderID is the builder ID, e.g. BuilderTitanID.
+	BuilderID     string           `json:"builder_id" yaml:"builder_id"`
+	FeeRecipients []common.Address `json:"fee_recipients,omitempty" yaml:"fee_recipients,omitempty"`
+	// ExtraData are matched case-insensitively as substrings of the block extraData.
+	ExtraData []string `json:"extra_data,omitempty" yaml:"extra_data,omitempty"`
+}
+
+// DefaultBuilderSignatures returns the known signatures of Ethereum mainnet builders.
+func DefaultBuilderSignatures() []BuilderSignature {
+	return []BuilderSignature{
+		{
+			BuilderID:     BuilderTitanID,
+			FeeRecipients: []common.Address{common.HexToAddress("0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97")},
+			ExtraData:     []string{"titanbuilder.xyz"},
+		},
+		{
+			BuilderID:     BuilderBeaverbuildID,
+			FeeRecipients: []common.Address{common.HexToAddress("0x95222290DD7278Aa3Ddd389Cc1E1d165CC4BAfe5")},
+			ExtraData:     []string{"beaverbuild.org"},
+		},
+		{
+			BuilderID:     BuilderRsyncID,
+			FeeRecipients: []common.Address{common.HexToAddress("0x1f9090aaE28b8a3dCeaDf281B0F12828e676c326")},
+			ExtraData:     []string{"rsync-builder.xyz"},
+		},
+		{BuilderID: BuilderNetID, ExtraData: []string{"buildernet"}},
+		{BuilderID: BuilderFlashbotID, ExtraData: []string{"illuminate dmocratize dstribute"}},
+		{BuilderID: BuilderBloxrouteID, ExtraData: []string{"bloxroute"}},
+		{BuilderID: BuilderQuasarID, ExtraData: []string{"quasar.win"}},
+		{BuilderID: BuilderJetbldrID, ExtraData: []string{"jetbldr"}},
+		{BuilderID: BuilderPenguinID, ExtraData: []string{"penguinbuild"}},
+		{BuilderID: BuilderBTCS, ExtraData: []string{"btcs"}},
+		{BuilderID: BuilderBobTheBuilder, ExtraData: []string{"bobthebuilder"}},
+	}
+}
+
+// BuilderAttributor maps blocks to builder IDs, fee recipients take precedence over extraData.
+type BuilderAttributor struct {
+	byFeeRecipient map[common.Address]string
+	extraData      []BuilderSignature
+}
+
+func NewBuilderAttributor(signatures ...BuilderSignature) *BuilderAttributor {
+	a := &BuilderAttributor{byFeeRecipient: make(map[common.Address]string)}
+	for _, sig := range signatures {
+		for _, recipient := range sig.FeeRecipients {
+			a.byFeeRecipient[recipient] = sig.BuilderID
+		}
+		if len(sig.ExtraData) == 0 {
+			continue
+		}
+		patterns := make([]string, 0, len(sig.ExtraData))
+		for _, pattern := range sig.ExtraData {
+			patterns = append(patterns, strings.ToLower(pattern))
+		}
+		a.extraData = append(a.extraData, BuilderSignature{BuilderID: sig.BuilderID, ExtraData: patterns})
+	}
+
+	return a
+}
+
+// Attribute returns the builder ID of the block, empty if no signature matches.
+func (a *BuilderAttributor) Attribute(header *types.Header) string {
+	if id, ok := a.byFeeRecipient[header.Coinbase]; ok {
+		return id
+	}
+
+	extra := strings.ToLower(string(header.Extra))
+	for _, sig := range a.extraData {
+		for _, pattern := range sig.ExtraData {
+			if strings.Contains(extra, pattern) {
+				return sig.BuilderID
+			}
+		}
+	}
+
+	return ""
+}
+
+// BlockAttribution is the builder of a mined block, BuilderID is empty for unknown builders.
+type BlockAttribution struct {
+	BlockNumber  uint64
+	FeeRecipient common.Address
+	BuilderID    string
+}
+
+// MissReason explains why a bundle submitted to a builder did not land with that builder.
+type MissReason string
+
+const (
+	// MissReasonBuilderLost means the builder did not win the target block.
+	MissReasonBuilderLost MissReason = "builder_lost"
+	// MissReasonNotIncluded means the builder won the target block without including the bundle.
+	MissReasonNotIncluded MissReason = "not_included"
+	// MissReasonLandedElsewhere means the bundle landed in a block of another builder.
+	MissReasonLandedElsewhere MissReason = "landed_elsewhere"
+	// MissReasonFrontRun means a nonce of the bundle was consumed by another transaction.
+	MissReasonFrontRun MissReason = "front_run"
+)
+
+// BuilderStats is the performance of one builder over a block range.
+type BuilderStats struct {
+	BuilderID string
+	BlocksWon int
+	// MarketShare is the share of the blocks of the range won by the builder, unknown builders included.
+	MarketShare float64
+	// Submitted counts the tracked submissions, Pending those whose outcome is not known yet.
+	Submitted int
+	Pending   int
+	Landed    int
+	// Unattributed counts the landed submissions whose block builder is unknown,
+	// they are neither Landed nor Missed.
+	Unattributed int
+	// LandingRate is Landed over Submitted.
+	LandingRate float64
+	Missed      map[MissReason]int
+}
+
+type BuilderReport struct {
+	FromBlock uint64
+	ToBlock   uint64
+	Blocks    int
+	// UnknownBlocks are the blocks no signature matched.
+	UnknownBlocks int
+	// Builders is sorted by blocks won, then by builder ID.
+	Builders []*BuilderStats
+}
+
+// Builder returns the stats of a builder ID, nil if it neither won a block nor received a submission.
+func (r BuilderReport) Builder(id string) *BuilderStats {
+	for _, stats := range r.Builders {
+		if stats.BuilderID == id {
+			return stats
+		}
+	}
+
+	return nil
+}
+
+// BuilderAnalyzer attributes the blocks of a range to builders and joins them with our submission log.
+type BuilderAnalyzer struct {
+	reader     HeaderReader
+	attributor *BuilderAttributor
+}
+
+func NewBuilderAnalyzer(reader HeaderReader, attributor *BuilderAttributor) *BuilderAnalyzer {
+	return &BuilderAnalyzer{
+		reader:     reader,
+		attributor: attributor,
+	}
+}
+
+// AttributeRange attributes the blocks in [fromBlock, toBlock].
+func (a *BuilderAnalyzer) AttributeRange(ctx context.Context, fromBlock, toBlock uint64) ([]BlockAttribution, error) {
+	if toBlock < fromBlock {
+		return nil, ErrInvalidMaxBlock
+	}
+
+	blocks := make([]BlockAttribution, 0, toBlock-fromBlock+1)
+	for number := fromBlock; number <= toBlock; number++ {
+		block, err := a.attributeBlock(ctx, number)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+func (a *BuilderAnalyzer) attributeBlock(ctx context.Context, number uint64) (BlockAttribution, error) {
+	header, err := a.reader.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return BlockAttribution{}, fmt.Errorf("get header %d: %w", number, err)
+	}
+
+	return BlockAttribution{
+		BlockNumber:  number,
+		FeeRecipient: header.Coinbase,
+		BuilderID:    a.attributor.Attribute(header),
+	}, nil
+}
+
+// Analyze attributes the blocks in [fromBlock, toBlock] and reports the submissions targeting them.
+// The blocks after toBlock that a submission landed in or targeted are attributed as well.
+func (a *BuilderAnalyzer) Analyze(
+	ctx context.Context, fromBlock, toBlock uint64, submissions []tradingtypes.TrackedExecuteBundle,
+) (BuilderReport, error) {
+	blocks, err := a.AttributeRange(ctx, fromBlock, toBlock)
+	if err != nil {
+		return BuilderReport{}, err
+	}
+
+	for _, number := range outsideBlocks(fromBlock, toBlock, submissions) {
+		block, err := a.attributeBlock(ctx, number)
+		if err != nil {
+			return BuilderReport{}, err
+		}
+		blocks = append(blocks, block)
+	}
+
+	return BuildBuilderReport(fromBlock, toBlock, blocks, submissions), nil
+}
+
+// outsideBlocks returns the sorted blocks out of [fromBlock, toBlock] needed to report the submissions
+// targeting the range: the landed blocks and the target blocks after toBlock.
+func outsideBlocks(fromBlock, toBlock uint64, submissions []tradingtypes.TrackedExecuteBundle) []uint64 {
+	needed := make(map[uint64]struct{})
+	for _, sub := range submissions {
+		if sub.BuilderName == "" || sub.BlockNumber < fromBlock || sub.BlockNumber > toBlock {
+			continue
+		}
+		switch BundleStatus(sub.Status) {
+		case BundleStatusLanded:
+			if sub.LandedBlockNumber < fromBlock || sub.LandedBlockNumber > toBlock {
+				needed[sub.LandedBlockNumber] = struct{}{}
+			}
+		case BundleStatusExpired:
+			for number := toBlock + 1; number <= sub.MaxBlockNumber; number++ {
+				needed[number] = struct{}{}
+			}
+		}
+	}
+
+	numbers := make([]uint64, 0, len(needed))
+	for number := range needed {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	return numbers
+}
+
+// BuildBuilderReport joins attributed blocks with submissions, one TrackedExecuteBundle per builder and bundle
+// whose BuilderName is the builder ID and whose outcome was set by BundleTrackResult.FillTrackedExecuteBundle.
+// Submissions targeting blocks outside [fromBlock, toBlock] or without BuilderName are ignored, an expired
+// submission was not included if its builder won any block between BlockNumber and MaxBlockNumber.
+// Blocks outside the range only attribute the submissions, blocks should hold the landed blocks and the
+// target blocks of the submissions, a landed submission whose block is missing or unknown is Unattributed.
+func BuildBuilderReport(
+	fromBlock, toBlock uint64, blocks []BlockAttribution, submissions []tradingtypes.TrackedExecuteBundle,
+) BuilderReport {
+	report := BuilderReport{FromBlock: fromBlock, ToBlock: toBlock}
+	builders := make(map[string]*BuilderStats)
+	stats := func(id string) *BuilderStats {
+		s, ok := builders[id]
+		if !ok {
+			s = &BuilderStats{BuilderID: id, Missed: make(map[MissReason]int)}
+			builders[id] = s
+		}
+		return s
+	}
+
+	winners := make(map[uint64]string, len(blocks))
+	for _, block := range blocks {
+		winners[block.BlockNumber] = block.BuilderID
+		if block.BlockNumber < fromBlock || block.BlockNumber > toBlock {
+			continue
+		}
+		report.Blocks++
+		if block.BuilderID == "" {
+			report.UnknownBlocks++
+			continue
+		}
+		stats(block.BuilderID).BlocksWon++
+	}
+
+	for _, sub := range submissions {
+		if sub.BuilderName == "" || sub.BlockNumber < fromBlock || sub.BlockNumber > toBlock {
+			continue
+		}
+		s := stats(sub.BuilderName)
+		switch BundleStatus(sub.Status) {
+		case BundleStatusLanded:
+			s.Submitted++
+			switch winners[sub.LandedBlockNumber] {
+			case "":
+				s.Unattributed++
+			case sub.BuilderName:
+				s.Landed++
+			default:
+				s.Missed[MissReasonLandedElsewhere]++
+			}
+		case BundleStatusFrontRun:
+			s.Submitted++
+			s.Missed[MissReasonFrontRun]++
+		case BundleStatusExpired:
+			s.Submitted++
+			if wonAny(winners, sub.BuilderName, sub.BlockNumber, max(sub.MaxBlockNumber, sub.BlockNumber)) {
+				s.Missed[MissReasonNotIncluded]++
+			} else {
+				s.Missed[MissReasonBuilderLost]++
+			}
+		default:
+			s.Pending++
+		}
+	}
+
+	for _, s := range builders {
+		if report.Blocks != 0 {
+			s.MarketShare = float64(s.BlocksWon) / float64(report.Blocks)
+		}
+		if s.Submitted != 0 {
+			s.LandingRate = float64(s.Landed) / float64(s.Submitted)
+		}
+		report.Builders = append(report.Builders, s)
+	}
+	sort.Slice(report.Builders, func(i, j int) bool {
+		if report.Builders[i].BlocksWon != report.Builders[j].BlocksWon {
+			return report.Builders[i].BlocksWon > report.Builders[j].BlocksWon
+		}
+		return report.Builders[i].BuilderID < report.Builders[j].BuilderID
+	})
+
+	return report
+}
+
+// wonAny reports whether the builder won a block of [fromBlock, toBlock].
+func wonAny(winners map[uint64]string, builderID string, fromBlock, toBlock uint64) bool {
+	for number := fromBlock; number <= toBlock; number++ {
+		if winners[number] == builderID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,155 @@
+package mev_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+type headers map[uint64]*types.Header
+
+func (h headers) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := h[number.Uint64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+
+	return header, nil
+}
+
+func TestBuilderAttributor(t *testing.T) {
+	titan := common.HexToAddress("0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97")
+	a := mev.NewBuilderAttributor(mev.DefaultBuilderSignatures()...)
+
+	require.Equal(t, mev.BuilderTitanID, a.Attribute(&types.Header{Coinbase: titan}))
+	require.Equal(t, mev.BuilderNetID, a.Attribute(&types.Header{Extra: []byte("BuilderNet (Flashbots)")}))
+	require.Empty(t, a.Attribute(&types.Header{Extra: []byte("geth")}))
+
+	// fee recipients take precedence over extraData
+	require.Equal(t, mev.BuilderTitanID, a.Attribute(&types.Header{Coinbase: titan, Extra: []byte("beaverbuild.org")}))
+}
+
+func TestBuilderAnalyzer(t *testing.T) {
+	titan := common.HexToAddress("0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97")
+	chain := headers{
+		100: {Coinbase: titan},
+		101: {Extra: []byte("beaverbuild.org")},
+		102: {Coinbase: titan},
+		103: {Extra: []byte("unknown")},
+	}
+	analyzer := mev.NewBuilderAnalyzer(chain, mev.NewBuilderAttributor(mev.DefaultBuilderSignatures()...))
+
+	landed := string(mev.BundleStatusLanded)
+	expired := string(mev.BundleStatusExpired)
+	report, err := analyzer.Analyze(context.Background(), 100, 103, []tradingtypes.TrackedExecuteBundle{
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderTitanID, BlockNumber: 100}, Status: landed, LandedBlockNumber: 100},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderBeaverbuildID, BlockNumber: 100}, Status: landed, LandedBlockNumber: 100},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderTitanID, BlockNumber: 101}, Status: expired},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderBeaverbuildID, BlockNumber: 101}, Status: expired},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderTitanID, BlockNumber: 102}, Status: string(mev.BundleStatusFrontRun)},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderRsyncID, BlockNumber: 103}, Status: string(mev.BundleStatusPending)},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderRsyncID, BlockNumber: 104}, Status: expired},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, report.Blocks)
+	require.Equal(t, 1, report.UnknownBlocks)
+	require.Len(t, report.Builders, 3)
+	require.Equal(t, mev.BuilderTitanID, report.Builders[0].BuilderID)
+
+	titanStats := report.Builder(mev.BuilderTitanID)
+	require.Equal(t, 2, titanStats.BlocksWon)
+	require.InDelta(t, 0.5, titanStats.MarketShare, 1e-9)
+	require.Equal(t, 3, titanStats.Submitted)
+	require.Equal(t, 1, titanStats.Landed)
+	require.InDelta(t, 1.0/3, titanStats.LandingRate, 1e-9)
+	require.Equal(t, map[mev.MissReason]int{
+		mev.MissReasonBuilderLost: 1,
+		mev.MissReasonFrontRun:    1,
+	}, titanStats.Missed)
+
+	beaverStats := report.Builder(mev.BuilderBeaverbuildID)
+	require.Equal(t, 1, beaverStats.BlocksWon)
+	require.Equal(t, 0, beaverStats.Landed)
+	require.Equal(t, map[mev.MissReason]int{
+		mev.MissReasonLandedElsewhere: 1,
+		mev.MissReasonNotIncluded:     1,
+	}, beaverStats.Missed)
+
+	rsyncStats := report.Builder(mev.BuilderRsyncID)
+	require.Equal(t, 0, rsyncStats.Submitted)
+	require.Equal(t, 1, rsyncStats.Pending)
+
+	// titan won the second target block of the expired bundle
+	report, err = analyzer.Analyze(context.Background(), 101, 103, []tradingtypes.TrackedExecuteBundle{
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderTitanID, BlockNumber: 101}, MaxBlockNumber: 102, Status: expired},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderBeaverbuildID, BlockNumber: 102}, MaxBlockNumber: 103, Status: expired},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[mev.MissReason]int{
+		mev.MissReasonNotIncluded: 1,
+	}, report.Builder(mev.BuilderTitanID).Missed)
+	require.Equal(t, map[mev.MissReason]int{
+		mev.MissReasonBuilderLost: 1,
+	}, report.Builder(mev.BuilderBeaverbuildID).Missed)
+
+	_, err = analyzer.Analyze(context.Background(), 100, 104, nil)
+	require.ErrorIs(t, err, ethereum.NotFound)
+}
+
+func TestBuilderAnalyzer_BlocksAfterRange(t *testing.T) {
+	titan := common.HexToAddress("0x4838B106FCe9647Bdf1E7877BF73cE8B0BAD5f97")
+	chain := headers{
+		100: {Extra: []byte("beaverbuild.org")},
+		101: {Coinbase: titan},
+		102: {Extra: []byte("unknown")},
+	}
+	analyzer := mev.NewBuilderAnalyzer(chain, mev.NewBuilderAttributor(mev.DefaultBuilderSignatures()...))
+
+	landed := string(mev.BundleStatusLanded)
+	expired := string(mev.BundleStatusExpired)
+	report, err := analyzer.Analyze(context.Background(), 100, 100, []tradingtypes.TrackedExecuteBundle{
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderTitanID, BlockNumber: 100}, MaxBlockNumber: 101, Status: landed, LandedBlockNumber: 101},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderRsyncID, BlockNumber: 100}, MaxBlockNumber: 102, Status: landed, LandedBlockNumber: 102},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderBeaverbuildID, BlockNumber: 100}, MaxBlockNumber: 101, Status: landed, LandedBlockNumber: 101},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderTitanID, BlockNumber: 100}, MaxBlockNumber: 101, Status: expired},
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BlockNumber: 100}, Status: landed, LandedBlockNumber: 100},
+	})
+	require.NoError(t, err)
+
+	// the blocks fetched for the submissions are not part of the range
+	require.Equal(t, 1, report.Blocks)
+	require.Equal(t, 0, report.UnknownBlocks)
+	require.Len(t, report.Builders, 3)
+	require.Equal(t, 1, report.Builder(mev.BuilderBeaverbuildID).BlocksWon)
+
+	titanStats := report.Builder(mev.BuilderTitanID)
+	require.Equal(t, 0, titanStats.BlocksWon)
+	require.Equal(t, 2, titanStats.Submitted)
+	require.Equal(t, 1, titanStats.Landed)
+	require.Equal(t, map[mev.MissReason]int{
+		mev.MissReasonNotIncluded: 1,
+	}, titanStats.Missed)
+
+	rsyncStats := report.Builder(mev.BuilderRsyncID)
+	require.Equal(t, 1, rsyncStats.Unattributed)
+	require.Equal(t, 0, rsyncStats.Landed)
+	require.Empty(t, rsyncStats.Missed)
+
+	require.Equal(t, map[mev.MissReason]int{
+		mev.MissReasonLandedElsewhere: 1,
+	}, report.Builder(mev.BuilderBeaverbuildID).Missed)
+
+	// a landed block missing from the attributed blocks is not counted as landed elsewhere
+	report = mev.BuildBuilderReport(100, 100, nil, []tradingtypes.TrackedExecuteBundle{
+		{ExecuteBundle: tradingtypes.ExecuteBundle{BuilderName: mev.BuilderTitanID, BlockNumber: 100}, Status: landed, LandedBlockNumber: 105},
+	})
+	require.Equal(t, 1, report.Builder(mev.BuilderTitanID).Unattributed)
+	require.Empty(t, report.Builder(mev.BuilderTitanID).Missed)
+}
@@ -0,0 +1,139 @@
+package mev
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// jsonRPCCodeLimitExceeded is the EIP-1474 code for "limit exceeded".
+const jsonRPCCodeLimitExceeded = -32005
+
+// BuilderError is an error returned by a builder, either as a non OK HTTP status or as a JSON-RPC error.
+// It unwraps to one of the sentinel builder errors (ErrRateLimited, ErrNonceTooLow, ...) when it can be classified.
+type BuilderError struct {
+	Kind       error
+	Code       int
+	Message    string
+	HTTPStatus int
+	Body       []byte
+}
+
+func (e *BuilderError) Error() string {
+	if e.HTTPStatus != http.StatusOK {
+		return fmt.Sprintf("not OK status, status: [%d], data: [%s]", e.HTTPStatus, string(e.Body))
+	}
+
+	return fmt.Sprintf("response error, code: [%d], message: [%s]", e.Code, e.Message)
+}
+
+func (e *BuilderError) Unwrap() error {
+	return e.Kind
+}
+
+// rpcErrorer is implemented by builder responses carrying a JSON-RPC error.
+type rpcErrorer interface {
+	rpcError() ErrorResponse
+}
+
+func (r SendBundleResponse) rpcError() ErrorResponse                { return r.Error }
+func (r SendPrivateRawTransactionResponse) rpcError() ErrorResponse { return r.Error }
+func (r SendRawTransactionResponse) rpcError() ErrorResponse        { return r.Error }
+func (r MerkleSendBundleResponse) rpcError() ErrorResponse          { return r.Error }
+func (r FlashbotCancelBundleResponse) rpcError() ErrorResponse      { return r.Error }
+func (r TitanCancelBundleResponse) rpcError() ErrorResponse         { return r.Error }
+func (r BLXRSubmitBundleResponse) rpcError() ErrorResponse          { return r.Error }
+func (r BloxrouteBundleTraceResponse) rpcError() ErrorResponse      { return r.Error }
+func (r UserStatsResponse[T]) rpcError() ErrorResponse              { return r.Error }
+
+func (r GetBundleStatsResponse) rpcError() ErrorResponse {
+	return ErrorResponse{Code: r.Error.Code, Messange: r.Error.Messange}
+}
+
+// rawUserStatsResponse is the untyped response of Client.GetUserStats.
+type rawUserStatsResponse map[string]any
+
+func (r rawUserStatsResponse) rpcError() ErrorResponse {
+	var e ErrorResponse
+	fields, ok := r["error"].(map[string]any)
+	if !ok {
+		return e
+	}
+	if code, ok := fields["code"].(float64); ok {
+		e.Code = int(code)
+	}
+	e.Messange, _ = fields["message"].(string)
+
+	return e
+}
+
+func newBuilderError(httpStatus, code int, message string, body []byte) *BuilderError {
+	// non OK responses usually still carry a JSON-RPC error worth classifying
+	if message == "" && len(body) != 0 {
+		var resp struct {
+			Error ErrorResponse `json:"error"`
+		}
+		if err := json.Unmarshal(body, &resp); err == nil {
+			code, message = resp.Error.Code, resp.Error.Messange
+		}
+	}
+
+	return &BuilderError{
+		Kind:       classifyBuilderError(httpStatus, code, message),
+		Code:       code,
+		Message:    message,
+		HTTPStatus: httpStatus,
+		Body:       body,
+	}
+}
+
+// transportError wraps a failure to reach the builder, a context error is left as is.
+func transportError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("do request error: %w", err)
+	}
+
+	return fmt.Errorf("do request error: %w: %w", ErrBuilderUnavailable, err)
+}
+
+// nolint: cyclop
+func classifyBuilderError(httpStatus, code int, message string) error {
+	msg := strings.ToLower(message)
+	containsAny := func(subs ...string) bool {
+		for _, sub := range subs {
+			if strings.Contains(msg, sub) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case httpStatus == http.StatusTooManyRequests, code == jsonRPCCodeLimitExceeded,
+		containsAny("rate limit", "too many requests", "exceeded the limit"):
+		return ErrRateLimited
+	case httpStatus == http.StatusUnauthorized, httpStatus == http.StatusForbidden,
+		containsAny("unauthorized", "forbidden", "invalid auth",
+			"x-flashbots-signature", "flashbots signature", "signature header"):
+		return ErrUnauthorized
+	case containsAny("already known", "already exists", "known bundle", "duplicate bundle"):
+		return ErrBundleAlreadyKnown
+	case containsAny("nonce too low", "invalid nonce", "nonce is too low"):
+		return ErrNonceTooLow
+	case containsAny("uuid") && containsAny("unknown", "not found", "does not exist"):
+		return ErrReplacementUUIDUnknown
+	case containsAny("block passed", "in the past", "past block", "block number too low", "too old"):
+		return ErrBlockPassed
+	case containsAny("revert", "simulation failed", "bundle simulation"):
+		return ErrSimulationReverted
+	case httpStatus == http.StatusBadGateway, httpStatus == http.StatusServiceUnavailable,
+		httpStatus == http.StatusGatewayTimeout,
+		containsAny("unavailable", "timeout", "timed out", "bad gateway", "overloaded"):
+		return ErrBuilderUnavailable
+	default:
+		return nil
+	}
+}
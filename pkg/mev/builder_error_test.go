@@ -0,0 +1,145 @@
+package mev_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderError_Classification(t *testing.T) {
+	tests := []struct {
+		name     string
+		response mevtest.Response
+		expected error
+	}{
+		{
+			name:     "http too many requests",
+			response: mevtest.Response{HTTPStatus: http.StatusTooManyRequests, Body: []byte("slow down")},
+			expected: mev.ErrRateLimited,
+		},
+		{
+			name:     "bundle already known",
+			response: mevtest.Response{Error: &mev.ErrorResponse{Code: -32000, Messange: "bundle already known"}},
+			expected: mev.ErrBundleAlreadyKnown,
+		},
+		{
+			name:     "nonce too low",
+			response: mevtest.Response{Error: &mev.ErrorResponse{Code: -32000, Messange: "err: nonce too low: address 0x1"}},
+			expected: mev.ErrNonceTooLow,
+		},
+		{
+			name:     "simulation reverted",
+			response: mevtest.Response{Error: &mev.ErrorResponse{Code: -32000, Messange: "execution reverted"}},
+			expected: mev.ErrSimulationReverted,
+		},
+		{
+			name:     "block passed",
+			response: mevtest.Response{Error: &mev.ErrorResponse{Code: -32602, Messange: "block number is in the past"}},
+			expected: mev.ErrBlockPassed,
+		},
+		{
+			name: "unauthorized with json body",
+			response: mevtest.Response{
+				HTTPStatus: http.StatusForbidden,
+				Error:      &mev.ErrorResponse{Code: -32600, Messange: "invalid flashbots signature"},
+			},
+			expected: mev.ErrUnauthorized,
+		},
+		{
+			name:     "replacement uuid unknown",
+			response: mevtest.Response{Error: &mev.ErrorResponse{Code: -32000, Messange: "replacementUuid not found"}},
+			expected: mev.ErrReplacementUUIDUnknown,
+		},
+		{
+			name:     "builder unavailable",
+			response: mevtest.Response{HTTPStatus: http.StatusBadGateway, Body: []byte("<html>bad gateway</html>")},
+			expected: mev.ErrBuilderUnavailable,
+		},
+	}
+
+	relay := mevtest.NewRelay(mev.BundleSenderTypeFlashbot)
+	defer relay.Close()
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relay.Script(mev.ETHSendBundleMethod, tt.response)
+			_, err := client.SendBundle(context.Background(), nil, 1, newSignedTx(t, 0))
+			require.ErrorIs(t, err, tt.expected)
+
+			var builderErr *mev.BuilderError
+			require.True(t, errors.As(err, &builderErr))
+			require.NotEmpty(t, builderErr.Body)
+			if tt.response.HTTPStatus != 0 {
+				require.Equal(t, tt.response.HTTPStatus, builderErr.HTTPStatus)
+			}
+			if tt.response.Error != nil {
+				require.Equal(t, tt.response.Error.Code, builderErr.Code)
+			}
+		})
+	}
+
+	// invalid tx signatures are bad input, not an authentication failure
+	for _, msg := range []string{"invalid transaction signature", "signature too short"} {
+		relay.Script(mev.ETHSendBundleMethod, mevtest.Response{Error: &mev.ErrorResponse{Code: -32000, Messange: msg}})
+		_, err := client.SendBundle(context.Background(), nil, 1, newSignedTx(t, 0))
+		require.Error(t, err)
+		require.NotErrorIs(t, err, mev.ErrUnauthorized, msg)
+	}
+	relay.Script(mev.ETHSendBundleMethod,
+		mevtest.Response{Error: &mev.ErrorResponse{Code: -32600, Messange: "invalid X-Flashbots-Signature"}})
+	_, err = client.SendBundle(context.Background(), nil, 1, newSignedTx(t, 0))
+	require.ErrorIs(t, err, mev.ErrUnauthorized)
+}
+
+func TestBuilderError_Stats(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeFlashbot)
+	defer relay.Close()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	client, err := mev.NewClient(relay.Client(), relay.URL(), key, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	rateLimited := mevtest.Response{Error: &mev.ErrorResponse{Code: -32005, Messange: "rate limit exceeded"}}
+
+	relay.Script(mev.FlashbotGetBundleStatsMethod, rateLimited)
+	_, err = client.GetBundleStats(context.Background(), 1, common.HexToHash("0x01"))
+	require.ErrorIs(t, err, mev.ErrRateLimited)
+
+	relay.Script(mev.FlashbotGetUserStats, rateLimited)
+	_, err = client.GetUserStats(context.Background(), false, 1)
+	require.ErrorIs(t, err, mev.ErrRateLimited)
+}
+
+func TestBuilderError_Senders(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeMerkle)
+	defer relay.Close()
+	rateLimited := mevtest.Response{HTTPStatus: http.StatusTooManyRequests}
+
+	relay.Script(mev.ETHSendBundleMethod, rateLimited)
+	_, err := mev.NewMerkleClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeMerkle, "").
+		SendBackrunBundle(context.Background(), nil, 1, 1, []common.Hash{{}}, nil, newSignedTx(t, 0))
+	require.ErrorIs(t, err, mev.ErrRateLimited)
+
+	relay.Script(mev.ETHSendRawTransaction, rateLimited)
+	_, err = mev.NewL2ChainSender(relay.Client(), relay.URL(), mev.BundleSenderTypeL2).
+		SendRawTransaction(context.Background(), newSignedTx(t, 0))
+	require.ErrorIs(t, err, mev.ErrRateLimited)
+
+	relay.Script(mev.BloxrouteSubmitBundleMethod, rateLimited)
+	_, err = mev.NewBloxrouteClient(relay.Client(), relay.URL(), "", nil).
+		SendBundle(context.Background(), nil, 1, newSignedTx(t, 0))
+	require.ErrorIs(t, err, mev.ErrRateLimited)
+
+	relay.Close()
+	_, err = mev.NewL2ChainSender(relay.Client(), relay.URL(), mev.BundleSenderTypeL2).
+		SendRawTransaction(context.Background(), newSignedTx(t, 0))
+	require.ErrorIs(t, err, mev.ErrBuilderUnavailable)
+}
@@ -0,0 +1,280 @@
+package mev
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BloxrouteBundleTraceMethod traces a bundle submitted to bloXroute through the builders it was sent to.
+const BloxrouteBundleTraceMethod = "blxr_bundle_trace"
+
+// FlashbotUserStats is the result of flashbots_getUserStats, payments are in wei.
+type FlashbotUserStats struct {
+	IsHighPriority       bool                 `json:"is_high_priority"`
+	AllTimeMinerPayments *tradingtypes.BigInt `json:"all_time_miner_payments"`
+	AllTimeGasSimulated  *tradingtypes.BigInt `json:"all_time_gas_simulated"`
+	Last7dMinerPayments  *tradingtypes.BigInt `json:"last_7d_miner_payments"`
+	Last7dGasSimulated   *tradingtypes.BigInt `json:"last_7d_gas_simulated"`
+	Last1dMinerPayments  *tradingtypes.BigInt `json:"last_1d_miner_payments"`
+	Last1dGasSimulated   *tradingtypes.BigInt `json:"last_1d_gas_simulated"`
+}
+
+func (s FlashbotUserStats) Summary() UserStatsSummary {
+	return UserStatsSummary{
+		SenderType:          BundleSenderTypeFlashbot,
+		IsHighPriority:      s.IsHighPriority,
+		AllTimePayments:     bigOrZero(s.AllTimeMinerPayments),
+		Last7dPayments:      bigOrZero(s.Last7dMinerPayments),
+		Last1dPayments:      bigOrZero(s.Last1dMinerPayments),
+		AllTimeGasSimulated: bigOrZero(s.AllTimeGasSimulated),
+		Last7dGasSimulated:  bigOrZero(s.Last7dGasSimulated),
+		Last1dGasSimulated:  bigOrZero(s.Last1dGasSimulated),
+	}
+}
+
+// FlashbotUserStatsV2 is the result of flashbots_getUserStatsV2.
+type FlashbotUserStatsV2 = ValidatorUserStats
+
+// TitanUserStats is the result of titan_getUserStats, titan serves the flashbots v2 stats.
+type TitanUserStats = ValidatorUserStats
+
+// ValidatorUserStats are the user stats reporting validator payments, payments are in wei.
+type ValidatorUserStats struct {
+	IsHighPriority           bool                 `json:"isHighPriority"`
+	AllTimeValidatorPayments *tradingtypes.BigInt `json:"allTimeValidatorPayments"`
+	AllTimeGasSimulated      *tradingtypes.BigInt `json:"allTimeGasSimulated"`
+	Last7dValidatorPayments  *tradingtypes.BigInt `json:"last7dValidatorPayments"`
+	Last7dGasSimulated       *tradingtypes.BigInt `json:"last7dGasSimulated"`
+	Last1dValidatorPayments  *tradingtypes.BigInt `json:"last1dValidatorPayments"`
+	Last1dGasSimulated       *tradingtypes.BigInt `json:"last1dGasSimulated"`
+}
+
+// Summary leaves SenderType empty, the same stats are served by several builders.
+func (s ValidatorUserStats) Summary() UserStatsSummary {
+	return UserStatsSummary{
+		IsHighPriority:      s.IsHighPriority,
+		AllTimePayments:     bigOrZero(s.AllTimeValidatorPayments),
+		Last7dPayments:      bigOrZero(s.Last7dValidatorPayments),
+		Last1dPayments:      bigOrZero(s.Last1dValidatorPayments),
+		AllTimeGasSimulated: bigOrZero(s.AllTimeGasSimulated),
+		Last7dGasSimulated:  bigOrZero(s.Last7dGasSimulated),
+		Last1dGasSimulated:  bigOrZero(s.Last1dGasSimulated),
+	}
+}
+
+// UserStatsSummary is the reputation of the searcher at a builder, comparable across builders.
+// Payments are the wei paid to validators, or miners for the legacy flashbots stats.
+type UserStatsSummary struct {
+	SenderType          BundleSenderType
+	IsHighPriority      bool
+	AllTimePayments     *big.Int
+	Last7dPayments      *big.Int
+	Last1dPayments      *big.Int
+	AllTimeGasSimulated *big.Int
+	Last7dGasSimulated  *big.Int
+	Last1dGasSimulated  *big.Int
+}
+
+type IUserStatsReporter interface {
+	GetUserStatsSummary(ctx context.Context, blockNumber uint64) (UserStatsSummary, error)
+	GetSenderType() BundleSenderType
+}
+
+var _ IUserStatsReporter = &Client{}
+
+// SummarizeUserStats collects the stats of every reporter, reporters failing are skipped and their errors joined.
+func SummarizeUserStats(
+	ctx context.Context, blockNumber uint64, reporters ...IUserStatsReporter,
+) ([]UserStatsSummary, error) {
+	summaries := make([]UserStatsSummary, 0, len(reporters))
+	var errs []error
+	for _, reporter := range reporters {
+		summary, err := reporter.GetUserStatsSummary(ctx, blockNumber)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", reporter.GetSenderType(), err))
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, errors.Join(errs...)
+}
+
+type UserStatsResponse[T any] struct {
+	Jsonrpc string        `json:"jsonrpc,omitempty"`
+	ID      int           `json:"id,omitempty"`
+	Result  T             `json:"result"`
+	Error   ErrorResponse `json:"error,omitempty"`
+}
+
+func (s *Client) GetFlashbotUserStats(ctx context.Context, blockNumber uint64) (FlashbotUserStats, error) {
+	return getUserStats[FlashbotUserStats](ctx, s, FlashbotGetUserStats, blockNumber)
+}
+
+func (s *Client) GetFlashbotUserStatsV2(ctx context.Context, blockNumber uint64) (FlashbotUserStatsV2, error) {
+	return getUserStats[FlashbotUserStatsV2](ctx, s, FlashbotGetUserStatsV2, blockNumber)
+}
+
+func (s *Client) GetTitanUserStats(ctx context.Context, blockNumber uint64) (TitanUserStats, error) {
+	return getUserStats[TitanUserStats](ctx, s, TitanGetUserStats, blockNumber)
+}
+
+// GetUserStatsSummary returns the titan stats for titan clients and the flashbots v2 stats otherwise.
+func (s *Client) GetUserStatsSummary(ctx context.Context, blockNumber uint64) (UserStatsSummary, error) {
+	var summary UserStatsSummary
+	switch s.senderType {
+	case BundleSenderTypeTitan:
+		stats, err := s.GetTitanUserStats(ctx, blockNumber)
+		if err != nil {
+			return UserStatsSummary{}, err
+		}
+		summary = stats.Summary()
+	default:
+		stats, err := s.GetFlashbotUserStatsV2(ctx, blockNumber)
+		if err != nil {
+			return UserStatsSummary{}, err
+		}
+		summary = stats.Summary()
+	}
+	summary.SenderType = s.senderType
+
+	return summary, nil
+}
+
+func getUserStats[T any](ctx context.Context, s *Client, method string, blockNumber uint64) (T, error) {
+	var t T
+	if s.flashbotKey == nil {
+		return t, ErrMissingPrivKey
+	}
+
+	params := GetUserStatsParams{}
+	params.SetBlockNumber(blockNumber)
+	req := SendRequest{
+		ID:      1,
+		JSONRPC: JSONRPC2,
+		Method:  method,
+		Params:  []any{params},
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return t, fmt.Errorf("marshal json error: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return t, fmt.Errorf("new http request error: %w", err)
+	}
+
+	signature, err := requestSignature(s.flashbotKey, reqBody)
+	if err != nil {
+		return t, fmt.Errorf("sign flashbot request error: %w", err)
+	}
+
+	resp, err := doRequest[UserStatsResponse[T]](s.c, httpReq, [2]string{"X-Flashbots-Signature", signature})
+	if err != nil {
+		return t, err
+	}
+
+	return resp.Result, nil
+}
+
+type BloxrouteBundleTraceParams struct {
+	BundleHash  string `json:"bundle_hash"`
+	BlockNumber string `json:"block_number,omitempty"`
+}
+
+type BloxrouteBundleTraceRequest struct {
+	ID     string                      `json:"id,omitempty"`
+	Method string                      `json:"method,omitempty"`
+	Params *BloxrouteBundleTraceParams `json:"params,omitempty"`
+}
+
+// BloxrouteBuilderTrace is the forwarding of a bundle to one builder.
+type BloxrouteBuilderTrace struct {
+	Builder string    `json:"builder"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+type BloxrouteBundleTrace struct {
+	BundleHash      string                  `json:"bundle_hash"`
+	BlockNumber     string                  `json:"block_number,omitempty"`
+	ReceivedAt      time.Time               `json:"received_at"`
+	IsSimulated     bool                    `json:"is_simulated"`
+	SimulationError string                  `json:"simulation_error,omitempty"`
+	Builders        []BloxrouteBuilderTrace `json:"builders,omitempty"`
+}
+
+type BloxrouteBundleTraceResponse struct {
+	Jsonrpc string               `json:"jsonrpc,omitempty"`
+	ID      int                  `json:"id,string,omitempty"`
+	Result  BloxrouteBundleTrace `json:"result,omitempty"`
+	Error   ErrorResponse        `json:"error,omitempty"`
+}
+
+// TraceBundle returns the bloXroute trace of the bundle, blockNumber is optional.
+func (s *BloxrouteClient) TraceBundle(
+	ctx context.Context, blockNumber uint64, bundleHash common.Hash,
+) (BloxrouteBundleTrace, error) {
+	p := &BloxrouteBundleTraceParams{BundleHash: bundleHash.Hex()}
+	if blockNumber != 0 {
+		p.BlockNumber = fmt.Sprintf("0x%x", blockNumber)
+	}
+	req := BloxrouteBundleTraceRequest{
+		ID:     strconv.Itoa(GetBundleStatsID),
+		Method: BloxrouteBundleTraceMethod,
+		Params: p,
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return BloxrouteBundleTrace{}, fmt.Errorf("marshal json error: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return BloxrouteBundleTrace{}, fmt.Errorf("new http request error: %w", err)
+	}
+
+	resp, err := doRequest[BloxrouteBundleTraceResponse](s.c, httpReq, [2]string{"Authorization", s.auth})
+	if err != nil {
+		return BloxrouteBundleTrace{}, err
+	}
+
+	return resp.Result, nil
+}
+
+// BundleStats converts the trace into bundle stats, the builders the bundle was sent to are considered
+// by their Builder name, bloXroute does not report builder pubkeys.
+func (t BloxrouteBundleTrace) BundleStats() GetBundleStatsResult {
+	stats := GetBundleStatsResult{
+		IsSimulated: t.IsSimulated,
+		ReceivedAt:  t.ReceivedAt,
+	}
+	for _, builder := range t.Builders {
+		stats.ConsideredByBuildersAt = append(stats.ConsideredByBuildersAt, &struct {
+			Pubkey    string    `json:"pubkey,omitempty"`
+			Builder   string    `json:"builder,omitempty"`
+			Timestamp time.Time `json:"timestamp"`
+		}{Builder: builder.Builder, Timestamp: builder.SentAt})
+	}
+
+	return stats
+}
+
+func bigOrZero(b *tradingtypes.BigInt) *big.Int {
+	if b == nil {
+		return new(big.Int)
+	}
+
+	return new(big.Int).Set(b.Int())
+}
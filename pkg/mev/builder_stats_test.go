@@ -0,0 +1,109 @@
+package mev_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetUserStatsSummary(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	relay := mevtest.NewRelay(mev.BundleSenderTypeTitan, mevtest.WithRequireSignature())
+	defer relay.Close()
+	relay.Script(mev.TitanGetUserStats, mevtest.Response{Result: map[string]any{
+		"isHighPriority":           true,
+		"allTimeValidatorPayments": "1280749594841588639",
+		"allTimeGasSimulated":      "30049470846",
+		"last7dValidatorPayments":  "142305510537954293",
+	}})
+	relay.Script(mev.FlashbotGetUserStats, mevtest.Response{Result: map[string]any{
+		"is_high_priority":        false,
+		"all_time_miner_payments": "10",
+		"last_1d_gas_simulated":   "21000",
+	}})
+
+	titan, err := mev.NewClient(relay.Client(), relay.URL(), key, mev.BundleSenderTypeTitan, false)
+	require.NoError(t, err)
+	flashbot, err := mev.NewClient(relay.Client(), relay.URL(), key, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+
+	summaries, err := mev.SummarizeUserStats(context.Background(), 100, titan, flashbot)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	require.Equal(t, mev.BundleSenderTypeTitan, summaries[0].SenderType)
+	require.True(t, summaries[0].IsHighPriority)
+	require.Equal(t, "1280749594841588639", summaries[0].AllTimePayments.String())
+	require.Equal(t, "142305510537954293", summaries[0].Last7dPayments.String())
+	require.Equal(t, big.NewInt(0), summaries[0].Last1dPayments)
+	require.Equal(t, mev.BundleSenderTypeFlashbot, summaries[1].SenderType)
+
+	reqs := relay.RequestsFor(mev.TitanGetUserStats)
+	require.Len(t, reqs, 1)
+	require.JSONEq(t, `[{"blockNumber":"0x64"}]`, string(reqs[0].Params))
+
+	stats, err := flashbot.GetFlashbotUserStats(context.Background(), 0)
+	require.NoError(t, err)
+	require.False(t, stats.IsHighPriority)
+	require.Equal(t, "10", stats.Summary().AllTimePayments.String())
+	require.Equal(t, "21000", stats.Summary().Last1dGasSimulated.String())
+
+	noKey, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	_, err = noKey.GetUserStatsSummary(context.Background(), 100)
+	require.ErrorIs(t, err, mev.ErrMissingPrivKey)
+}
+
+func TestBloxrouteClient_GetBundleStats(t *testing.T) {
+	archive, err := os.Open("testdata/blxr_bundle_trace.jsonl")
+	require.NoError(t, err)
+	defer archive.Close()
+	recs, err := mev.ReadRecordings(archive)
+	require.NoError(t, err)
+
+	replay := mev.NewReplayTransport(recs)
+	recorded := mev.NewBloxrouteClient(&http.Client{Transport: replay}, "https://api.blxrbdn.com", "auth", nil)
+	bundleHash := common.HexToHash("0x6f2c4e0b4d8c5e3f1a9b7d2e0c4a6b8d1e3f5a7c9b0d2e4f6a8c0e1b3d5f7a9c")
+	resp, err := recorded.GetBundleStats(context.Background(), 21989932, bundleHash)
+	require.NoError(t, err)
+	require.Zero(t, replay.Remaining())
+	require.True(t, resp.Result.IsSimulated)
+	require.Equal(t, time.Date(2025, 3, 4, 10, 15, 22, 418_000_000, time.UTC), resp.Result.ReceivedAt)
+	require.Len(t, resp.Result.ConsideredByBuildersAt, 3)
+	for i, builder := range []string{"flashbots", "beaverbuild", "titan"} {
+		require.Equal(t, builder, resp.Result.ConsideredByBuildersAt[i].Builder)
+		require.Empty(t, resp.Result.ConsideredByBuildersAt[i].Pubkey)
+	}
+	require.Equal(t, time.Date(2025, 3, 4, 10, 15, 22, 436_000_000, time.UTC),
+		resp.Result.ConsideredByBuildersAt[2].Timestamp)
+
+	relay := mevtest.NewRelay(mev.BundleSenderTypeBloxroute, mevtest.WithAuthorization("auth"))
+	defer relay.Close()
+	client := mev.NewBloxrouteClient(relay.Client(), relay.URL(), "auth", nil)
+	_, err = client.GetBundleStats(context.Background(), 100, bundleHash)
+	require.NoError(t, err)
+
+	reqs := relay.RequestsFor(mev.BloxrouteBundleTraceMethod)
+	require.Len(t, reqs, 1)
+	var params mev.BloxrouteBundleTraceParams
+	require.NoError(t, json.Unmarshal(reqs[0].Params, &params))
+	require.Equal(t, mev.BloxrouteBundleTraceParams{BundleHash: bundleHash.Hex(), BlockNumber: "0x64"}, params)
+
+	relay.Script(mev.BloxrouteBundleTraceMethod, mevtest.Response{
+		Error: &mev.ErrorResponse{Code: -32000, Messange: "bundle not found"},
+	})
+	_, err = client.TraceBundle(context.Background(), 0, bundleHash)
+	var builderErr *mev.BuilderError
+	require.ErrorAs(t, err, &builderErr)
+}
@@ -0,0 +1,152 @@
+package mev
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/KyberNetwork/tradinglib/pkg/convert"
+)
+
+const maxBPS = 10_000
+
+// BundleEconomics is the typed view of a simulation result of eth_callBundle or blxr_simulate_bundle.
+// Builder payment is the whole coinbase diff: the priority fees (GasFees) plus the direct transfers
+// (EthSentToCoinbase). Gross profit used by the methods is the searcher revenue in wei before any cost.
+type BundleEconomics struct {
+	GasUsed           uint64
+	GasFees           *big.Int
+	CoinbaseDiff      *big.Int
+	EthSentToCoinbase *big.Int
+	// BundleGasPrice is CoinbaseDiff / GasUsed, excluding the base fee.
+	BundleGasPrice *big.Int
+	BaseFee        *big.Int
+}
+
+// NewBundleEconomics parses r, whose amounts are decimal or 0x-prefixed hex strings.
+// Missing totals are derived from the others, baseFee is the base fee of the target block.
+func NewBundleEconomics(r SendBundleResult, baseFee *big.Int) (BundleEconomics, error) {
+	e := BundleEconomics{
+		BaseFee: new(big.Int),
+	}
+	if baseFee != nil {
+		e.BaseFee.Set(baseFee)
+	}
+	if r.TotalGasUsed > 0 {
+		e.GasUsed = uint64(r.TotalGasUsed)
+	} else {
+		for _, tx := range r.Results {
+			e.GasUsed += uint64(max(tx.GasUsed, 0))
+		}
+	}
+
+	var err error
+	if e.GasFees, err = parseAmount("gasFees", r.GasFees); err != nil {
+		return BundleEconomics{}, err
+	}
+	if e.CoinbaseDiff, err = parseAmount("coinbaseDiff", r.CoinbaseDiff); err != nil {
+		return BundleEconomics{}, err
+	}
+	if e.EthSentToCoinbase, err = parseAmount("ethSentToCoinbase", r.EthSentToCoinbase); err != nil {
+		return BundleEconomics{}, err
+	}
+	if e.BundleGasPrice, err = parseAmount("bundleGasPrice", r.BundleGasPrice); err != nil {
+		return BundleEconomics{}, err
+	}
+
+	switch {
+	case r.CoinbaseDiff == "":
+		e.CoinbaseDiff.Add(e.GasFees, e.EthSentToCoinbase)
+	case r.EthSentToCoinbase == "":
+		e.EthSentToCoinbase.Sub(e.CoinbaseDiff, e.GasFees)
+	case r.GasFees == "":
+		e.GasFees.Sub(e.CoinbaseDiff, e.EthSentToCoinbase)
+	}
+	if r.BundleGasPrice == "" && e.GasUsed != 0 {
+		e.BundleGasPrice.Quo(e.CoinbaseDiff, new(big.Int).SetUint64(e.GasUsed))
+	}
+
+	return e, nil
+}
+
+// EffectiveGasPrice is the price per gas the bundle pays including the base fee.
+func (e BundleEconomics) EffectiveGasPrice() *big.Int {
+	return new(big.Int).Add(e.BundleGasPrice, e.BaseFee)
+}
+
+// BaseFeeCost is the base fee burnt by the bundle.
+func (e BundleEconomics) BaseFeeCost() *big.Int {
+	return new(big.Int).Mul(e.BaseFee, new(big.Int).SetUint64(e.GasUsed))
+}
+
+// TotalCost is the base fee cost plus the builder payment.
+func (e BundleEconomics) TotalCost() *big.Int {
+	return new(big.Int).Add(e.BaseFeeCost(), e.CoinbaseDiff)
+}
+
+// NetProfit is grossProfit minus the base fee cost and the builder payment, it can be negative.
+func (e BundleEconomics) NetProfit(grossProfit *big.Int) *big.Int {
+	return new(big.Int).Sub(grossProfit, e.TotalCost())
+}
+
+// BuilderPaymentBPS is the share of the profit after base fee paid to the builder, in bps.
+// It returns 0 when there is no profit to share.
+func (e BundleEconomics) BuilderPaymentBPS(grossProfit *big.Int) int64 {
+	profit := new(big.Int).Sub(grossProfit, e.BaseFeeCost())
+	if profit.Sign() <= 0 {
+		return 0
+	}
+
+	return new(big.Int).Quo(new(big.Int).Mul(e.CoinbaseDiff, big.NewInt(maxBPS)), profit).Int64()
+}
+
+// BreakEvenTip is the highest priority fee per gas the bundle can pay, keeping the direct transfers,
+// before its net profit becomes negative. It returns 0 when the bundle is not profitable at any tip.
+func (e BundleEconomics) BreakEvenTip(grossProfit *big.Int) *big.Int {
+	if e.GasUsed == 0 {
+		return new(big.Int)
+	}
+	budget := new(big.Int).Sub(grossProfit, e.BaseFeeCost())
+	budget.Sub(budget, e.EthSentToCoinbase)
+	if budget.Sign() <= 0 {
+		return new(big.Int)
+	}
+
+	return budget.Quo(budget, new(big.Int).SetUint64(e.GasUsed))
+}
+
+// SuggestCoinbaseTransfer returns the direct transfer to the builder needed for the builder payment
+// to reach targetBPS of the profit after base fee, given the priority fees the bundle already pays.
+func (e BundleEconomics) SuggestCoinbaseTransfer(grossProfit *big.Int, targetBPS int64) *big.Int {
+	profit := new(big.Int).Sub(grossProfit, e.BaseFeeCost())
+	if profit.Sign() <= 0 {
+		return new(big.Int)
+	}
+	transfer := new(big.Int).Sub(convert.BPS(profit, targetBPS), e.GasFees)
+	if transfer.Sign() < 0 {
+		return new(big.Int)
+	}
+
+	return transfer
+}
+
+func parseAmount(field, s string) (*big.Int, error) {
+	if s == "" {
+		return new(big.Int), nil
+	}
+
+	var (
+		v  *big.Int
+		ok bool
+	)
+	if hex, found := strings.CutPrefix(s, "0x"); found {
+		v, ok = new(big.Int).SetString(hex, 16)
+	} else {
+		v, ok = new(big.Int).SetString(s, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid %s: %q", field, s)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,74 @@
+package mev_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleEconomics(t *testing.T) {
+	// 100k gas paying 2 gwei of tip plus 0.0008 ETH of direct transfer, at 10 gwei base fee
+	result := mev.SendBundleResult{
+		CoinbaseDiff:      "1000000000000000",
+		EthSentToCoinbase: "800000000000000",
+		GasFees:           "200000000000000",
+		TotalGasUsed:      100_000,
+	}
+	baseFee := big.NewInt(10e9)
+	grossProfit := big.NewInt(3e15)
+
+	e, err := mev.NewBundleEconomics(result, baseFee)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(10e9), e.BundleGasPrice)
+	require.Equal(t, big.NewInt(20e9), e.EffectiveGasPrice())
+	require.Equal(t, big.NewInt(1e15), e.BaseFeeCost())
+	require.Equal(t, big.NewInt(1e15), e.NetProfit(grossProfit))
+	require.Equal(t, int64(5000), e.BuilderPaymentBPS(grossProfit))
+	// (3e15 - 1e15 - 8e14) / 1e5
+	require.Equal(t, big.NewInt(12e9), e.BreakEvenTip(grossProfit))
+	// 90% of 2e15 minus the 2e14 of tips
+	require.Equal(t, big.NewInt(16e14), e.SuggestCoinbaseTransfer(grossProfit, 9000))
+	require.Zero(t, e.SuggestCoinbaseTransfer(grossProfit, 500).Sign())
+	require.Zero(t, e.BreakEvenTip(big.NewInt(1)).Sign())
+	require.Negative(t, e.NetProfit(big.NewInt(1)).Sign())
+
+	// hex amounts, missing totals are derived
+	e, err = mev.NewBundleEconomics(mev.SendBundleResult{
+		CoinbaseDiff: "0x2710",
+		GasFees:      "0x3e8",
+		Results:      []mev.SendBundleResults{{GasUsed: 60}, {GasUsed: 40}},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), e.GasUsed)
+	require.Equal(t, big.NewInt(9000), e.EthSentToCoinbase)
+	require.Equal(t, big.NewInt(100), e.BundleGasPrice)
+
+	_, err = mev.NewBundleEconomics(mev.SendBundleResult{CoinbaseDiff: "1.5"}, nil)
+	require.Error(t, err)
+}
+
+func TestBundleEconomics_BloxrouteSimulation(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeBloxroute)
+	defer relay.Close()
+	relay.Script(mev.BloxrouteSimulationBundleMethod, mevtest.Response{Result: mev.SendBundleResult{
+		BundleGasPrice:    "0x5",
+		CoinbaseDiff:      "500",
+		EthSentToCoinbase: "400",
+		GasFees:           "100",
+		TotalGasUsed:      100,
+	}})
+
+	resp, err := mev.NewBloxrouteClient(relay.Client(), relay.URL(), "auth", nil).
+		SimulateBundle(context.Background(), 10, newSignedTx(t, 0))
+	require.NoError(t, err)
+	require.Len(t, relay.RequestsFor(mev.BloxrouteSimulationBundleMethod), 1)
+
+	e, err := mev.NewBundleEconomics(resp.Result, big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(5), e.BundleGasPrice)
+	require.Equal(t, big.NewInt(6), e.EffectiveGasPrice())
+}
@@ -0,0 +1,168 @@
+package mev
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	BundleOptionRefundPercent   = "refundPercent"
+	BundleOptionRefundRecipient = "refundRecipient"
+	BundleOptionRefundTxHashes  = "refundTxHashes"
+	BundleOptionDroppingTxs     = "droppingTxHashes"
+	BundleOptionBuilders        = "builders"
+)
+
+// BundleOptionError is returned when a builder does not accept an option of a SendBundleV2Request.
+type BundleOptionError struct {
+	SenderType BundleSenderType
+	Option     string
+	Reason     string
+}
+
+func (e *BundleOptionError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: %s does not support %s, %s", ErrBundleOptionNotSupported, e.SenderType, e.Option, e.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s does not support %s", ErrBundleOptionNotSupported, e.SenderType, e.Option)
+}
+
+func (e *BundleOptionError) Unwrap() error {
+	return ErrBundleOptionNotSupported
+}
+
+type bundleOptionSupport struct {
+	refund         bool
+	refundTxHashes bool
+	// refundIndex builders refund a single tx given by its index in the bundle instead of its hash
+	refundIndex bool
+	droppingTxs bool
+	builders    bool
+}
+
+// options accepted in eth_sendBundle by each builder
+// nolint: gochecknoglobals
+var bundleOptionSupports = map[BundleSenderType]bundleOptionSupport{
+	BundleSenderTypeFlashbot:   {refund: true, refundTxHashes: true, droppingTxs: true, builders: true},
+	BundleSenderTypeBuilderNet: {refund: true, refundTxHashes: true, droppingTxs: true},
+	BundleSenderTypeTitan:      {refund: true, refundIndex: true, droppingTxs: true},
+	BundleSenderTypeBeaver:     {refund: true, refundTxHashes: true},
+}
+
+// SupportsBundleOption reports whether the builder accepts option, one of the BundleOption constants.
+func SupportsBundleOption(senderType BundleSenderType, option string) bool {
+	support := bundleOptionSupports[senderType]
+	switch option {
+	case BundleOptionRefundPercent, BundleOptionRefundRecipient:
+		return support.refund
+	case BundleOptionRefundTxHashes:
+		return support.refundTxHashes || support.refundIndex
+	case BundleOptionDroppingTxs:
+		return support.droppingTxs
+	case BundleOptionBuilders:
+		return support.builders
+	default:
+		return false
+	}
+}
+
+// CheckBundleOptions returns a *BundleOptionError for the first option of req not accepted by senderType.
+func CheckBundleOptions(req SendBundleV2Request, senderType BundleSenderType) error {
+	options := []struct {
+		name string
+		set  bool
+	}{
+		{BundleOptionRefundPercent, req.RefundPercent != nil},
+		{BundleOptionRefundRecipient, req.RefundRecipient != nil},
+		{BundleOptionRefundTxHashes, req.RefundTxHashes != nil},
+		{BundleOptionDroppingTxs, req.DroppingTxs != nil},
+		{BundleOptionBuilders, req.Builders != nil},
+	}
+	for _, option := range options {
+		if option.set && !SupportsBundleOption(senderType, option.name) {
+			return &BundleOptionError{SenderType: senderType, Option: option.name}
+		}
+	}
+
+	return nil
+}
+
+// SetBundleOptions sets the builder specific options of req in the shape expected by senderType,
+// txs are the bundle txs used to locate refunded txs for builders expecting an index.
+func (p *SendBundleParams) SetBundleOptions(
+	req SendBundleV2Request, senderType BundleSenderType, txs ...*types.Transaction,
+) error {
+	support := bundleOptionSupports[senderType]
+	unsupported := func(option string) error {
+		return &BundleOptionError{SenderType: senderType, Option: option}
+	}
+
+	if req.RefundPercent != nil {
+		if !support.refund {
+			return unsupported(BundleOptionRefundPercent)
+		}
+		if *req.RefundPercent < 0 || *req.RefundPercent > 99 {
+			return fmt.Errorf("refund percent must be between 0 and 99, got %d", *req.RefundPercent)
+		}
+		p.RefundPercent = req.RefundPercent
+	}
+	if req.RefundRecipient != nil {
+		if !support.refund {
+			return unsupported(BundleOptionRefundRecipient)
+		}
+		p.RefundRecipient = req.RefundRecipient.Hex()
+	}
+	if req.RefundTxHashes != nil {
+		switch {
+		case support.refundTxHashes:
+			p.RefundTxHashes = *req.RefundTxHashes
+		case support.refundIndex:
+			index, err := refundIndex(senderType, *req.RefundTxHashes, txs)
+			if err != nil {
+				return err
+			}
+			p.RefundIndex = &index
+		default:
+			return unsupported(BundleOptionRefundTxHashes)
+		}
+	}
+	if req.DroppingTxs != nil {
+		if !support.droppingTxs {
+			return unsupported(BundleOptionDroppingTxs)
+		}
+		p.DroppingTxs = req.DroppingTxs
+	}
+	if req.Builders != nil {
+		if !support.builders {
+			return unsupported(BundleOptionBuilders)
+		}
+		p.Builders = *req.Builders
+	}
+
+	return nil
+}
+
+func refundIndex(senderType BundleSenderType, hashes []string, txs []*types.Transaction) (int, error) {
+	if len(hashes) != 1 {
+		return 0, &BundleOptionError{
+			SenderType: senderType,
+			Option:     BundleOptionRefundTxHashes,
+			Reason:     "only one refunded tx is allowed",
+		}
+	}
+	refunded := common.HexToHash(hashes[0])
+	for i, tx := range txs {
+		if tx.Hash() == refunded {
+			return i, nil
+		}
+	}
+
+	return 0, &BundleOptionError{
+		SenderType: senderType,
+		Option:     BundleOptionRefundTxHashes,
+		Reason:     fmt.Sprintf("refunded tx %s is not in the bundle", hashes[0]),
+	}
+}
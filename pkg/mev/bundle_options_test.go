@@ -0,0 +1,125 @@
+package mev_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func sentBundleParams(t *testing.T, relay *mevtest.Relay) map[string]any {
+	t.Helper()
+	requests := relay.RequestsFor(mev.ETHSendBundleMethod)
+	require.NotEmpty(t, requests)
+	var params []map[string]any
+	require.NoError(t, json.Unmarshal(requests[len(requests)-1].Params, &params))
+
+	return params[0]
+}
+
+func TestSendBundleV2_BundleOptions(t *testing.T) {
+	tx0, tx1 := newSignedTx(t, 0), newSignedTx(t, 1)
+	percent := 90
+	recipient := common.HexToAddress("0x01")
+	req := mev.SendBundleV2Request{
+		RefundPercent:   &percent,
+		RefundRecipient: &recipient,
+		RefundTxHashes:  &[]string{tx1.Hash().Hex()},
+		DroppingTxs:     &[]string{tx0.Hash().Hex()},
+	}
+
+	relay := mevtest.NewRelay(mev.BundleSenderTypeBuilderNet)
+	defer relay.Close()
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeBuilderNet, false)
+	require.NoError(t, err)
+	_, err = client.SendBundleV2(context.Background(), req, tx0, tx1)
+	require.NoError(t, err)
+	params := sentBundleParams(t, relay)
+	require.EqualValues(t, 90, params["refundPercent"])
+	require.Equal(t, recipient.Hex(), params["refundRecipient"])
+	require.Equal(t, []any{tx1.Hash().Hex()}, params["refundTxHashes"])
+	require.Equal(t, []any{tx0.Hash().Hex()}, params["droppingTxHashes"])
+	require.NotContains(t, params, "refundIndex")
+
+	// titan refunds a tx given by its index in the bundle
+	titan, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeTitan, false)
+	require.NoError(t, err)
+	_, err = titan.SendBundleV2(context.Background(), req, tx0, tx1)
+	require.NoError(t, err)
+	params = sentBundleParams(t, relay)
+	require.EqualValues(t, 1, params["refundIndex"])
+	require.NotContains(t, params, "refundTxHashes")
+
+	// hashes are compared whatever their case
+	upper := mev.SendBundleV2Request{RefundTxHashes: &[]string{"0x" + strings.ToUpper(tx1.Hash().Hex()[2:])}}
+	_, err = titan.SendBundleV2(context.Background(), upper, tx0, tx1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, sentBundleParams(t, relay)["refundIndex"])
+
+	_, err = titan.SendBundleV2(context.Background(), req, tx0)
+	var notInBundle *mev.BundleOptionError
+	require.ErrorAs(t, err, &notInBundle)
+	require.Equal(t, mev.BundleOptionRefundTxHashes, notInBundle.Option)
+
+	// beaverbuild does not drop txs
+	beaver, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeBeaver, false)
+	require.NoError(t, err)
+	_, err = beaver.SendBundleV2(context.Background(), req, tx0, tx1)
+	require.ErrorIs(t, err, mev.ErrBundleOptionNotSupported)
+	var optionErr *mev.BundleOptionError
+	require.True(t, errors.As(err, &optionErr))
+	require.Equal(t, mev.BundleOptionDroppingTxs, optionErr.Option)
+	require.Equal(t, mev.BundleSenderTypeBeaver, optionErr.SenderType)
+
+	sent := len(relay.RequestsFor(mev.ETHSendBundleMethod))
+	_, err = titan.SendBundleV2(context.Background(), mev.SendBundleV2Request{
+		Builders: &[]string{"flashbots"},
+	}, tx0)
+	require.ErrorIs(t, err, mev.ErrBundleOptionNotSupported)
+	require.Len(t, relay.RequestsFor(mev.ETHSendBundleMethod), sent)
+}
+
+func TestBloxrouteClient_SendBundleV2_BundleOptions(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeBloxroute)
+	defer relay.Close()
+	client := mev.NewBloxrouteClient(relay.Client(), relay.URL(), "auth", nil)
+
+	percent := 90
+	recipient := common.HexToAddress("0x01")
+	hashes := []string{common.HexToHash("0x02").Hex()}
+	testCases := []struct {
+		option string
+		req    mev.SendBundleV2Request
+	}{
+		{mev.BundleOptionRefundPercent, mev.SendBundleV2Request{RefundPercent: &percent}},
+		{mev.BundleOptionRefundRecipient, mev.SendBundleV2Request{RefundRecipient: &recipient}},
+		{mev.BundleOptionRefundTxHashes, mev.SendBundleV2Request{RefundTxHashes: &hashes}},
+		{mev.BundleOptionDroppingTxs, mev.SendBundleV2Request{DroppingTxs: &hashes}},
+		{mev.BundleOptionBuilders, mev.SendBundleV2Request{Builders: &[]string{"titan"}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.option, func(t *testing.T) {
+			_, err := client.SendBundleV2(context.Background(), tc.req, newSignedTx(t, 0))
+			var optionErr *mev.BundleOptionError
+			require.ErrorAs(t, err, &optionErr)
+			require.Equal(t, tc.option, optionErr.Option)
+			require.Equal(t, mev.BundleSenderTypeBloxroute, optionErr.SenderType)
+		})
+	}
+	require.Empty(t, relay.RequestsFor(mev.BloxrouteSubmitBundleMethod))
+
+	_, err := client.SendBundleV2(context.Background(), mev.SendBundleV2Request{}, newSignedTx(t, 0))
+	require.NoError(t, err)
+}
+
+func TestSupportsBundleOption(t *testing.T) {
+	require.True(t, mev.SupportsBundleOption(mev.BundleSenderTypeFlashbot, mev.BundleOptionBuilders))
+	require.True(t, mev.SupportsBundleOption(mev.BundleSenderTypeTitan, mev.BundleOptionRefundTxHashes))
+	require.False(t, mev.SupportsBundleOption(mev.BundleSenderTypeRsync, mev.BundleOptionRefundPercent))
+}
@@ -0,0 +1,178 @@
+package mev
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+const nonceResyncTimeout = 5 * time.Second
+
+// ScheduledBundle is a bundle to submit for every block of [FromBlock, ToBlock].
+type ScheduledBundle struct {
+	// UUID is the replacement uuid shared by all submissions, a random one is generated when empty.
+	UUID      string
+	FromBlock uint64
+	ToBlock   uint64
+	Txs       []*types.Transaction
+	// Nonces are the reservations of the bundle txs, they are kept across target blocks, confirmed when
+	// the bundle lands, resynced from the chain when it gets front-run and released otherwise.
+	Nonces []*NonceReservation
+}
+
+type ScheduleResult struct {
+	BundleTrackResult
+	UUID string
+	// Submissions holds the broadcast results of each target block.
+	Submissions map[uint64]BroadcastResults
+	// Cancellations holds the CancelBundle results once the bundle landed or got front-run.
+	Cancellations BroadcastResults
+}
+
+// BundleScheduler resubmits a bundle on each new head until it lands, gets front-run or expires.
+type BundleScheduler struct {
+	tracker     *BundleTracker
+	broadcaster *Broadcaster
+}
+
+// NewBundleScheduler polls the chain head every pollInterval, a zero interval defaults to one second.
+func NewBundleScheduler(reader ChainReader, broadcaster *Broadcaster, pollInterval time.Duration) *BundleScheduler {
+	return &BundleScheduler{
+		tracker:     NewBundleTracker(reader, pollInterval),
+		broadcaster: broadcaster,
+	}
+}
+
+// Schedule blocks until the bundle lands, gets front-run or the head reaches ToBlock.
+// On each new head N in the window it sends the bundle targeting N+1 with the same replacement uuid,
+// the remaining submissions are cancelled as soon as a bundle tx is mined or a bundle nonce is consumed.
+func (s *BundleScheduler) Schedule(ctx context.Context, b ScheduledBundle) (ScheduleResult, error) {
+	if b.ToBlock < b.FromBlock {
+		return ScheduleResult{}, ErrInvalidMaxBlock
+	}
+	if b.UUID == "" {
+		b.UUID = uuid.NewString()
+	}
+	tracked, err := NewTrackedBundle(b.FromBlock, b.ToBlock, nil, b.Txs...)
+	if err != nil {
+		return ScheduleResult{}, err
+	}
+
+	result := ScheduleResult{
+		BundleTrackResult: BundleTrackResult{
+			Status:    BundleStatusPending,
+			TxHashes:  tracked.TxHashes,
+			FromBlock: b.FromBlock,
+			ToBlock:   b.ToBlock,
+			Stats:     make(map[string]GetBundleStatsResult),
+		},
+		UUID:        b.UUID,
+		Submissions: make(map[uint64]BroadcastResults),
+	}
+
+	defer settleNonces(ctx, b.Nonces, &result)
+
+	ticker := time.NewTicker(s.tracker.pollInterval)
+	defer ticker.Stop()
+
+	var lastHead uint64
+	for {
+		head, err := s.tracker.reader.HeaderByNumber(ctx, nil)
+		// errors are retried on the next tick, the context bounds the schedule
+		if err == nil && head.Number.Uint64() > lastHead {
+			if err = s.tracker.check(ctx, tracked, head, &result.BundleTrackResult); err == nil {
+				lastHead = head.Number.Uint64()
+				switch result.Status {
+				case BundleStatusPending:
+					s.submit(ctx, b, lastHead+1, &tracked, &result)
+				case BundleStatusLanded, BundleStatusFrontRun:
+					result.Cancellations = s.broadcaster.CancelBundle(ctx, b.UUID)
+					return result, nil
+				default:
+					return result, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return result, errors.Join(ctx.Err(), err)
+			}
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *BundleScheduler) submit(
+	ctx context.Context, b ScheduledBundle, target uint64, tracked *TrackedBundle, result *ScheduleResult,
+) {
+	if target < b.FromBlock || target > b.ToBlock {
+		return
+	}
+	if _, ok := result.Submissions[target]; ok {
+		return
+	}
+
+	results := s.broadcaster.SendBundleV2(ctx, SendBundleV2Request{
+		BlockNumber: &target,
+		UUID:        &b.UUID,
+	}, b.Txs...)
+	result.Submissions[target] = results
+
+	// keep the latest bundle hash of each builder for the stats
+	tracked.Submissions = tracked.Submissions[:0]
+	for _, sender := range s.broadcaster.senders {
+		r, ok := results[SenderName(sender)]
+		if !ok || r.Err != nil || r.Response.Result.BundleHash == "" {
+			continue
+		}
+		tracked.Submissions = append(tracked.Submissions, BundleSubmission{
+			Sender:     sender,
+			BundleHash: common.HexToHash(r.Response.Result.BundleHash),
+		})
+	}
+}
+
+func settleNonces(ctx context.Context, nonces []*NonceReservation, result *ScheduleResult) {
+	switch result.Status {
+	case BundleStatusLanded:
+		for _, r := range nonces {
+			r.Confirm()
+		}
+	case BundleStatusFrontRun:
+		// another tx consumed a reserved nonce, the chain nonce known by the manager is stale
+		type managedAccount struct {
+			m       *NonceManager
+			account common.Address
+		}
+		// the Schedule ctx is usually done by now, the resync must not depend on it
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), nonceResyncTimeout)
+		defer cancel()
+		synced := make(map[managedAccount]bool)
+		for _, r := range nonces {
+			key := managedAccount{m: r.m, account: r.Account}
+			if synced[key] {
+				continue
+			}
+			synced[key] = true
+			if err := r.m.Resync(ctx, r.Account); err != nil {
+				// which nonces got consumed is unknown, the next Reserve resyncs first
+				r.m.MarkStale(r.Account)
+			}
+		}
+		// reservations still above the chain nonce are free again
+		for _, r := range nonces {
+			r.Release()
+		}
+	default:
+		for _, r := range nonces {
+			r.Release()
+		}
+	}
+}
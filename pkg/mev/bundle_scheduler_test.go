@@ -0,0 +1,120 @@
+package mev_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newScheduler(t *testing.T, chain *fakeChain) (*mev.BundleScheduler, *mevtest.Relay) {
+	t.Helper()
+	relay := mevtest.NewRelay(mev.BundleSenderTypeFlashbot)
+	t.Cleanup(relay.Close)
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	b, err := mev.NewBroadcaster([]mev.IBundleSender{client}, nil)
+	require.NoError(t, err)
+
+	return mev.NewBundleScheduler(chain, b, 5*time.Millisecond), relay
+}
+
+func TestBundleScheduler_Landed(t *testing.T) {
+	tx := newSignedTx(t, 0)
+	chain := &fakeChain{
+		head:     99,
+		receipts: map[common.Hash]*types.Receipt{},
+		nonces:   map[common.Address]uint64{},
+	}
+	scheduler, relay := newScheduler(t, chain)
+
+	type outcome struct {
+		result mev.ScheduleResult
+		err    error
+	}
+	done := make(chan outcome)
+	go func() {
+		result, err := scheduler.Schedule(context.Background(), mev.ScheduledBundle{
+			UUID:      "uuid",
+			FromBlock: 100,
+			ToBlock:   102,
+			Txs:       []*types.Transaction{tx},
+		})
+		done <- outcome{result: result, err: err}
+	}()
+
+	sent := func(n int) func() bool {
+		return func() bool { return len(relay.RequestsFor(mev.ETHSendBundleMethod)) == n }
+	}
+	require.Eventually(t, sent(1), time.Second, time.Millisecond)
+	chain.advance(nil)
+	require.Eventually(t, sent(2), time.Second, time.Millisecond)
+	chain.advance(func(c *fakeChain) {
+		c.receipts[tx.Hash()] = &types.Receipt{BlockNumber: big.NewInt(101), TransactionIndex: 3}
+	})
+
+	out := <-done
+	require.NoError(t, out.err)
+	result := out.result
+	require.Equal(t, mev.BundleStatusLanded, result.Status)
+	require.Equal(t, uint64(101), result.BlockNumber)
+	require.Len(t, result.Submissions, 2)
+	require.NoError(t, result.Submissions[100].Err())
+	require.NoError(t, result.Submissions[101].Err())
+	require.NoError(t, result.Cancellations.Err())
+	require.Len(t, relay.RequestsFor(mev.ETHCancelBundleMethod), 1)
+
+	for i, req := range relay.RequestsFor(mev.ETHSendBundleMethod) {
+		var params []mev.SendBundleParams
+		require.NoError(t, json.Unmarshal(req.Params, &params))
+		require.Equal(t, "uuid", params[0].ReplacementUUID)
+		require.Len(t, params[0].Txs, 1)
+		require.Equal(t, []string{"0x64", "0x65"}[i], params[0].BlockNumber)
+	}
+}
+
+func TestBundleScheduler_FrontRunAndExpired(t *testing.T) {
+	tx := newSignedTx(t, 0)
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	require.NoError(t, err)
+	chain := &fakeChain{
+		head:     100,
+		receipts: map[common.Hash]*types.Receipt{},
+		nonces:   map[common.Address]uint64{},
+	}
+	scheduler, relay := newScheduler(t, chain)
+
+	go func() {
+		for len(relay.RequestsFor(mev.ETHSendBundleMethod)) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		chain.advance(func(c *fakeChain) { c.nonces[from] = 1 })
+	}()
+	result, err := scheduler.Schedule(context.Background(), mev.ScheduledBundle{
+		FromBlock: 100,
+		ToBlock:   101,
+		Txs:       []*types.Transaction{tx},
+	})
+	require.NoError(t, err)
+	require.Equal(t, mev.BundleStatusFrontRun, result.Status)
+	require.NotEmpty(t, result.UUID)
+	require.Len(t, relay.RequestsFor(mev.ETHCancelBundleMethod), 1)
+
+	// the head already reached the end of the window
+	result, err = scheduler.Schedule(context.Background(), mev.ScheduledBundle{
+		FromBlock: 100,
+		ToBlock:   101,
+		Txs:       []*types.Transaction{newSignedTx(t, 0)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, mev.BundleStatusExpired, result.Status)
+	require.Empty(t, result.Submissions)
+	require.Nil(t, result.Cancellations)
+}
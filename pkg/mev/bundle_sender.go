@@ -72,6 +72,9 @@ func (s *Client) SendBundleV2(
 	req SendBundleV2Request,
 	txs ...*types.Transaction,
 ) (SendBundleResponse, error) {
+	if err := checkBlobSupport(s.senderType, txs); err != nil {
+		return SendBundleResponse{}, err
+	}
 	p := new(SendBundleParams).
 		SetTransactions(txs...)
 
@@ -93,6 +96,9 @@ func (s *Client) SendBundleV2(
 	if s.senderType == BundleSenderTypeFlashbot {
 		p = p.SetStateBlockNumber("latest")
 	}
+	if err := p.SetBundleOptions(req, s.senderType, txs...); err != nil {
+		return SendBundleResponse{}, err
+	}
 
 	if err := p.Err(); err != nil {
 		return SendBundleResponse{}, err
@@ -160,35 +166,17 @@ func (s *Client) CancelBundle(
 		headers = append(headers, [2]string{"X-Flashbots-Signature", signature})
 	}
 
-	// do
-	var errResp ErrorResponse
+	// do, json-rpc errors are checked by doRequest
 	switch s.senderType {
 	case BundleSenderTypeFlashbot:
-		resp, err := doRequest[FlashbotCancelBundleResponse](s.c, httpReq, headers...)
-		if err != nil {
-			return err
-		}
-		errResp = resp.Error
+		_, err = doRequest[FlashbotCancelBundleResponse](s.c, httpReq, headers...)
 	case BundleSenderTypeTitan:
-		resp, err := doRequest[TitanCancelBundleResponse](s.c, httpReq, headers...)
-		if err != nil {
-			return err
-		}
-		errResp = resp.Error
+		_, err = doRequest[TitanCancelBundleResponse](s.c, httpReq, headers...)
 	default:
-		resp, err := doRequest[SendBundleResponse](s.c, httpReq, headers...)
-		if err != nil {
-			return err
-		}
-		errResp = resp.Error
+		_, err = doRequest[SendBundleResponse](s.c, httpReq, headers...)
 	}
 
-	// check
-	if len(errResp.Messange) != 0 {
-		return fmt.Errorf("response error, code: [%d], message: [%s]", errResp.Code, errResp.Messange)
-	}
-
-	return nil
+	return err
 }
 
 func (s *Client) SimulateBundle(
@@ -243,6 +231,9 @@ func (s *Client) sendBundle(
 	txs []*types.Transaction,
 	hexEncodedTxs []string,
 ) (SendBundleResponse, error) {
+	if err := checkBlobSupport(s.senderType, txs); err != nil {
+		return SendBundleResponse{}, err
+	}
 	p := new(SendBundleParams).
 		SetBlockNumber(blockNumber).
 		SetTransactions(txs...).
@@ -297,11 +288,6 @@ func (s *Client) sendRawBundle(ctx context.Context, method string, p *SendBundle
 		return SendBundleResponse{}, err
 	}
 
-	if len(resp.Error.Messange) != 0 {
-		return SendBundleResponse{}, fmt.Errorf("response error, code: [%d], message: [%s]",
-			resp.Error.Code, resp.Error.Messange)
-	}
-
 	// for some case, blink builder resp contains "" wrap around the bundle hash like
 	/*
 		2024-12-24T03:58:38Z	info	operator/broadcaster.go:465	send bundle (multiple)
@@ -363,11 +349,6 @@ func (s *Client) SendPrivateRawTransaction(
 		return SendPrivateRawTransactionResponse{}, err
 	}
 
-	if len(resp.Error.Messange) != 0 {
-		return SendPrivateRawTransactionResponse{}, fmt.Errorf("response error, code: [%d], message: [%s]",
-			resp.Error.Code, resp.Error.Messange)
-	}
-
 	return resp, nil
 }
 
@@ -421,7 +402,7 @@ func (s *Client) GetUserStats(
 		headers = append(headers, [2]string{"X-Flashbots-Signature", signature})
 	}
 
-	resp, err := doRequest[map[string]any](s.c, httpReq, headers...)
+	resp, err := doRequest[rawUserStatsResponse](s.c, httpReq, headers...)
 	if err != nil {
 		return nil, err
 	}
@@ -494,6 +475,14 @@ type SendBundleParams struct {
 	// From 3rd November, 2025: must be set explicitly to be eligible for refunds.
 	BuilderNetRefundAddress string `json:"builderNetRefundAddress"`
 
+	// builder extensions, set from SendBundleV2Request by SetBundleOptions
+	RefundPercent   *int      `json:"refundPercent,omitempty"`
+	RefundRecipient string    `json:"refundRecipient,omitempty"`
+	RefundTxHashes  []string  `json:"refundTxHashes,omitempty"`
+	RefundIndex     *int      `json:"refundIndex,omitempty"`
+	DroppingTxs     *[]string `json:"droppingTxHashes,omitempty"`
+	Builders        []string  `json:"builders,omitempty"`
+
 	Errors []error `json:"-"` // check when building bundle
 }
 
@@ -534,6 +523,11 @@ func (p *SendBundleParams) SetTransactions(txs ...*types.Transaction) *SendBundl
 
 	transactions := make([]string, 0, len(txs))
 	for _, tx := range txs {
+		if err := validateBundleTx(tx); err != nil {
+			p.Errors = append(p.Errors, err)
+			continue
+		}
+		// blob txs are encoded in their network form, with the sidecar
 		txBin, err := tx.MarshalBinary()
 		if err != nil {
 			p.Errors = append(p.Errors, fmt.Errorf("marshal tx: %w", err))
@@ -0,0 +1,238 @@
+package mev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const defaultTrackerPollInterval = time.Second
+
+// ChainReader is the chain access needed to follow a bundle, *ethclient.Client satisfies it.
+type ChainReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+type BundleStatus string
+
+const (
+	BundleStatusPending BundleStatus = "pending"
+	BundleStatusLanded  BundleStatus = "landed"
+	BundleStatusExpired BundleStatus = "expired"
+	// BundleStatusFrontRun means a nonce used by the bundle was consumed by another transaction.
+	BundleStatusFrontRun BundleStatus = "front_run"
+)
+
+// BundleSubmission is a bundle accepted by one builder, builders may return different bundle hashes.
+type BundleSubmission struct {
+	Sender     IBundleSender
+	BundleHash common.Hash
+}
+
+// TrackedBundle describes a submitted bundle.
+// TxHashes must only contain our own transactions, not the pending transactions we backrun.
+type TrackedBundle struct {
+	TxHashes []common.Hash
+	// Nonces holds the nonce of the first transaction of each sender, used to detect front-running.
+	Nonces      map[common.Address]uint64
+	FromBlock   uint64
+	ToBlock     uint64
+	Submissions []BundleSubmission
+}
+
+// NewTrackedBundle builds a TrackedBundle targeting [fromBlock, toBlock] from the signed bundle transactions.
+func NewTrackedBundle(
+	fromBlock, toBlock uint64,
+	submissions []BundleSubmission,
+	txs ...*types.Transaction,
+) (TrackedBundle, error) {
+	b := TrackedBundle{
+		TxHashes:    make([]common.Hash, 0, len(txs)),
+		Nonces:      make(map[common.Address]uint64, len(txs)),
+		FromBlock:   fromBlock,
+		ToBlock:     toBlock,
+		Submissions: submissions,
+	}
+	for _, tx := range txs {
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return TrackedBundle{}, fmt.Errorf("get sender of tx %s: %w", tx.Hash(), err)
+		}
+		if nonce, ok := b.Nonces[from]; !ok || tx.Nonce() < nonce {
+			b.Nonces[from] = tx.Nonce()
+		}
+		b.TxHashes = append(b.TxHashes, tx.Hash())
+	}
+
+	return b, nil
+}
+
+type BundleTrackResult struct {
+	Status       BundleStatus
+	BlockNumber  uint64
+	FeeRecipient common.Address
+	// Position is the index in the block of the first bundle transaction.
+	Position uint
+	TxHashes []common.Hash
+	// FromBlock and ToBlock are the first and last target blocks of the bundle.
+	FromBlock uint64
+	ToBlock   uint64
+	// Stats holds the last bundle stats returned by builders that support it, keyed by SenderName.
+	Stats map[string]GetBundleStatsResult
+}
+
+// FillTrackedExecuteBundle sets the tx hashes, target blocks and inclusion outcome of eb.
+func (r BundleTrackResult) FillTrackedExecuteBundle(eb *tradingtypes.TrackedExecuteBundle) {
+	eb.TxHashes = make([]string, 0, len(r.TxHashes))
+	for _, h := range r.TxHashes {
+		eb.TxHashes = append(eb.TxHashes, h.Hex())
+	}
+	eb.BlockNumber = r.FromBlock
+	eb.MaxBlockNumber = r.ToBlock
+	eb.Status = string(r.Status)
+	if r.Status == BundleStatusLanded {
+		eb.LandedBlockNumber = r.BlockNumber
+		eb.FeeRecipient = r.FeeRecipient.Hex()
+		eb.Position = r.Position
+	}
+}
+
+type BundleTracker struct {
+	reader       ChainReader
+	pollInterval time.Duration
+}
+
+// NewBundleTracker polls the chain head every pollInterval, a zero interval defaults to one second.
+func NewBundleTracker(reader ChainReader, pollInterval time.Duration) *BundleTracker {
+	if pollInterval <= 0 {
+		pollInterval = defaultTrackerPollInterval
+	}
+
+	return &BundleTracker{
+		reader:       reader,
+		pollInterval: pollInterval,
+	}
+}
+
+// Track blocks until the bundle lands, expires or gets front-run.
+// The target range is capped to MaxBlockFromTarget blocks from FromBlock.
+func (t *BundleTracker) Track(ctx context.Context, b TrackedBundle) (BundleTrackResult, error) {
+	if b.ToBlock < b.FromBlock {
+		return BundleTrackResult{}, ErrInvalidMaxBlock
+	}
+	if b.ToBlock >= b.FromBlock+MaxBlockFromTarget {
+		b.ToBlock = b.FromBlock + MaxBlockFromTarget - 1
+	}
+
+	result := BundleTrackResult{
+		Status:    BundleStatusPending,
+		TxHashes:  b.TxHashes,
+		FromBlock: b.FromBlock,
+		ToBlock:   b.ToBlock,
+		Stats:     make(map[string]GetBundleStatsResult, len(b.Submissions)),
+	}
+
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	var lastHead uint64
+	for {
+		head, err := t.reader.HeaderByNumber(ctx, nil)
+		// errors are retried on the next tick, the context bounds the tracking
+		if err == nil && head.Number.Uint64() > lastHead {
+			if err = t.check(ctx, b, head, &result); err == nil {
+				lastHead = head.Number.Uint64()
+				if result.Status != BundleStatusPending {
+					return result, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return result, errors.Join(ctx.Err(), err)
+			}
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *BundleTracker) check(
+	ctx context.Context, b TrackedBundle, head *types.Header, result *BundleTrackResult,
+) error {
+	headNumber := head.Number.Uint64()
+	if headNumber >= b.FromBlock {
+		t.pollStats(ctx, b, min(headNumber, b.ToBlock), result)
+	}
+
+	for _, txHash := range b.TxHashes {
+		receipt, err := t.reader.TransactionReceipt(ctx, txHash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("get receipt of tx %s: %w", txHash, err)
+		}
+		// the tx was mined outside the bundle, e.g. sent publicly, the nonce check below reports it
+		if blockNumber := receipt.BlockNumber.Uint64(); blockNumber < b.FromBlock || blockNumber > b.ToBlock {
+			continue
+		}
+
+		header, err := t.reader.HeaderByNumber(ctx, receipt.BlockNumber)
+		if err != nil {
+			return fmt.Errorf("get header %s: %w", receipt.BlockNumber, err)
+		}
+		result.Status = BundleStatusLanded
+		result.BlockNumber = receipt.BlockNumber.Uint64()
+		result.FeeRecipient = header.Coinbase
+		result.Position = receipt.TransactionIndex
+
+		return nil
+	}
+
+	for from, nonce := range b.Nonces {
+		chainNonce, err := t.reader.NonceAt(ctx, from, head.Number)
+		if err != nil {
+			return fmt.Errorf("get nonce of %s: %w", from, err)
+		}
+		if chainNonce > nonce {
+			result.Status = BundleStatusFrontRun
+			result.BlockNumber = headNumber
+			return nil
+		}
+	}
+
+	if headNumber >= b.ToBlock {
+		result.Status = BundleStatusExpired
+		result.BlockNumber = headNumber
+	}
+
+	return nil
+}
+
+// pollStats ignores errors, stats are informative and most builders do not support them.
+func (t *BundleTracker) pollStats(
+	ctx context.Context, b TrackedBundle, blockNumber uint64, result *BundleTrackResult,
+) {
+	for _, s := range b.Submissions {
+		if s.Sender == nil {
+			continue
+		}
+		resp, err := s.Sender.GetBundleStats(ctx, blockNumber, s.BundleHash)
+		if err != nil || len(resp.Error.Messange) != 0 {
+			continue
+		}
+		result.Stats[SenderName(s.Sender)] = resp.Result
+	}
+}
@@ -0,0 +1,171 @@
+package mev_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChain struct {
+	mu       sync.Mutex
+	head     uint64
+	coinbase common.Address
+	receipts map[common.Hash]*types.Receipt
+	nonces   map[common.Address]uint64
+}
+
+func (c *fakeChain) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if number == nil {
+		number = new(big.Int).SetUint64(c.head)
+	}
+
+	return &types.Header{Number: number, Coinbase: c.coinbase}, nil
+}
+
+func (c *fakeChain) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+
+	return r, nil
+}
+
+func (c *fakeChain) NonceAt(_ context.Context, account common.Address, _ *big.Int) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.nonces[account], nil
+}
+
+func (c *fakeChain) advance(f func(c *fakeChain)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.head++
+	if f != nil {
+		f(c)
+	}
+}
+
+func newSignedTx(t *testing.T, nonce uint64) *types.Transaction {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     nonce,
+		To:        &to,
+		Gas:       21000,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+	})
+	require.NoError(t, err)
+
+	return tx
+}
+
+func TestBundleTracker_Landed(t *testing.T) {
+	tx := newSignedTx(t, 0)
+	chain := &fakeChain{
+		head:     99,
+		coinbase: common.HexToAddress("0xbeef"),
+		receipts: map[common.Hash]*types.Receipt{},
+		nonces:   map[common.Address]uint64{},
+	}
+	b, err := mev.NewTrackedBundle(100, 102, []mev.BundleSubmission{
+		{Sender: stubSender{senderType: mev.BundleSenderTypeTitan}},
+	}, tx)
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		chain.advance(nil)
+		time.Sleep(20 * time.Millisecond)
+		chain.advance(func(c *fakeChain) {
+			c.receipts[tx.Hash()] = &types.Receipt{BlockNumber: big.NewInt(101), TransactionIndex: 3}
+		})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	result, err := mev.NewBundleTracker(chain, 5*time.Millisecond).Track(ctx, b)
+	require.NoError(t, err)
+	require.Equal(t, mev.BundleStatusLanded, result.Status)
+	require.Equal(t, uint64(101), result.BlockNumber)
+	require.Equal(t, uint(3), result.Position)
+
+	var eb tradingtypes.TrackedExecuteBundle
+	result.FillTrackedExecuteBundle(&eb)
+	require.Equal(t, "landed", eb.Status)
+	require.Equal(t, uint64(100), eb.BlockNumber)
+	require.Equal(t, uint64(102), eb.MaxBlockNumber)
+	require.Equal(t, uint64(101), eb.LandedBlockNumber)
+	require.Equal(t, chain.coinbase.Hex(), eb.FeeRecipient)
+	require.Equal(t, []string{tx.Hash().Hex()}, []string(eb.TxHashes))
+}
+
+func TestBundleTracker_Expired(t *testing.T) {
+	tx := newSignedTx(t, 0)
+	chain := &fakeChain{head: 105, receipts: map[common.Hash]*types.Receipt{}, nonces: map[common.Address]uint64{}}
+	b, err := mev.NewTrackedBundle(100, 110, nil, tx)
+	require.NoError(t, err)
+
+	result, err := mev.NewBundleTracker(chain, time.Millisecond).Track(context.Background(), b)
+	require.NoError(t, err)
+	require.Equal(t, mev.BundleStatusExpired, result.Status)
+}
+
+func TestBundleTracker_FrontRun(t *testing.T) {
+	tx := newSignedTx(t, 7)
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), tx)
+	require.NoError(t, err)
+	chain := &fakeChain{
+		head:     100,
+		receipts: map[common.Hash]*types.Receipt{},
+		nonces:   map[common.Address]uint64{from: 8},
+	}
+	b, err := mev.NewTrackedBundle(100, 101, nil, tx)
+	require.NoError(t, err)
+
+	result, err := mev.NewBundleTracker(chain, time.Millisecond).Track(context.Background(), b)
+	require.NoError(t, err)
+	require.Equal(t, mev.BundleStatusFrontRun, result.Status)
+}
+
+func TestBundleTracker_LandedOutOfRange(t *testing.T) {
+	tx := newSignedTx(t, 7)
+	from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), tx)
+	require.NoError(t, err)
+	chain := &fakeChain{
+		head:     101,
+		receipts: map[common.Hash]*types.Receipt{tx.Hash(): {BlockNumber: big.NewInt(99)}},
+		nonces:   map[common.Address]uint64{from: 7},
+	}
+	b, err := mev.NewTrackedBundle(100, 101, nil, tx)
+	require.NoError(t, err)
+
+	result, err := mev.NewBundleTracker(chain, time.Millisecond).Track(context.Background(), b)
+	require.NoError(t, err)
+	require.Equal(t, mev.BundleStatusExpired, result.Status)
+
+	// the nonce consumed before the target blocks is a front-run
+	chain.nonces[from] = 8
+	result, err = mev.NewBundleTracker(chain, time.Millisecond).Track(context.Background(), b)
+	require.NoError(t, err)
+	require.Equal(t, mev.BundleStatusFrontRun, result.Status)
+}
@@ -6,10 +6,41 @@ import (
 
 // nolint: gochecknoglobals
 var (
-	ErrMethodNotSupport    = fmt.Errorf("method not support")
-	ErrMevShareClientNil   = fmt.Errorf("mev share client is nil")
-	ErrInvalidLenTx        = fmt.Errorf("only one tx is allowed")
-	ErrMissingPrivKey      = fmt.Errorf("missing private key")
-	ErrInvalidMaxBlock     = fmt.Errorf("max block number must be greater than block number")
-	ErrInvalidLenPendingTx = fmt.Errorf("only one pending tx is allowed")
+	ErrMethodNotSupport         = fmt.Errorf("method not support")
+	ErrMevShareClientNil        = fmt.Errorf("mev share client is nil")
+	ErrInvalidLenTx             = fmt.Errorf("only one tx is allowed")
+	ErrMissingPrivKey           = fmt.Errorf("missing private key")
+	ErrInvalidMaxBlock          = fmt.Errorf("max block number must be greater than block number")
+	ErrInvalidLenPendingTx      = fmt.Errorf("only one pending tx is allowed")
+	ErrDuplicateSenderName      = fmt.Errorf("duplicate sender name")
+	ErrMissingSignature         = fmt.Errorf("missing signature")
+	ErrMalformedSignature       = fmt.Errorf("malformed signature")
+	ErrSignatureMismatch        = fmt.Errorf("signature does not match address")
+	ErrCircuitOpen              = fmt.Errorf("circuit open")
+	ErrMissingBlobSidecar       = fmt.Errorf("blob tx without sidecar")
+	ErrBlobFeeCapTooLow         = fmt.Errorf("blob fee cap lower than blob base fee")
+	ErrTooManyBlobs             = fmt.Errorf("too many blobs")
+	ErrBlobTxNotSupported       = fmt.Errorf("blob tx not supported by builder")
+	ErrEmptyAuthList            = fmt.Errorf("set code tx without authorization")
+	ErrAuthNonceConflict        = fmt.Errorf("authorization nonce conflicts with bundle txs")
+	ErrReplayExhausted          = fmt.Errorf("no recording left to replay")
+	ErrReplayMismatch           = fmt.Errorf("request does not match recording")
+	ErrBundleOptionNotSupported = fmt.Errorf("bundle option not supported")
+	ErrInvalidCapability        = fmt.Errorf("invalid capability")
+	ErrBundleAlreadySubmitted   = fmt.Errorf("bundle already submitted by an earlier attempt")
+)
+
+// builder errors, returned wrapped in a *BuilderError
+// nolint: gochecknoglobals
+var (
+	ErrRateLimited            = fmt.Errorf("rate limited")
+	ErrBundleAlreadyKnown     = fmt.Errorf("bundle already known")
+	ErrNonceTooLow            = fmt.Errorf("nonce too low")
+	ErrSimulationReverted     = fmt.Errorf("simulation reverted")
+	ErrBlockPassed            = fmt.Errorf("block passed")
+	ErrUnauthorized           = fmt.Errorf("unauthorized")
+	ErrReplacementUUIDUnknown = fmt.Errorf("replacement uuid unknown")
+	ErrBuilderUnavailable     = fmt.Errorf("builder unavailable")
+	ErrConditionRejected      = fmt.Errorf("transaction condition rejected")
+	ErrConditionCostExceeded  = fmt.Errorf("transaction condition cost exceeded")
 )
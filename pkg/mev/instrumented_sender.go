@@ -0,0 +1,411 @@
+package mev
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/flashbots/mev-share-node/mevshare"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	MetricSenderRequestDuration = "mev_sender_request_duration_ms"
+	MetricSenderRequests        = "mev_sender_requests"
+	MetricSenderBundleSize      = "mev_sender_bundle_size"
+
+	tracerName = "github.com/KyberNetwork/tradinglib/pkg/mev"
+
+	// methods are labelled with their Go name, the JSON-RPC method depends on the wrapped sender
+	methodSendBundle                = "SendBundle"
+	methodSendBundleV2              = "SendBundleV2"
+	methodSendBundleHex             = "SendBundleHex"
+	methodCancelBundle              = "CancelBundle"
+	methodSendPrivateRawTransaction = "SendPrivateRawTransaction"
+	methodSimulateBundle            = "SimulateBundle"
+	methodGetBundleStats            = "GetBundleStats"
+	methodGetUserStats              = "GetUserStats"
+	methodSendBackrunBundle         = "SendBackrunBundle"
+	methodMevSimulateBundle         = "MevSimulateBundle"
+	methodSendRawTransaction        = "SendRawTransaction"
+)
+
+// span and metric attribute keys
+const (
+	AttrSenderType  = attribute.Key("mev.sender_type")
+	AttrMethod      = attribute.Key("mev.method")
+	AttrResult      = attribute.Key("mev.result")
+	AttrErrorClass  = attribute.Key("mev.error_class")
+	AttrBundleHash  = attribute.Key("mev.bundle_hash")
+	AttrBlockNumber = attribute.Key("mev.block_number")
+	AttrTxHash      = attribute.Key("mev.tx_hash")
+)
+
+type InstrumentOption func(instrumentOptions) instrumentOptions
+
+type instrumentOptions struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider sets the provider spans are created from, the global one is used by default.
+func WithTracerProvider(tp trace.TracerProvider) InstrumentOption {
+	return func(opt instrumentOptions) instrumentOptions {
+		opt.tracerProvider = tp
+		return opt
+	}
+}
+
+// WithMeterProvider sets the provider metrics are recorded with, pkg/metrics is used by default.
+func WithMeterProvider(mp metric.MeterProvider) InstrumentOption {
+	return func(opt instrumentOptions) instrumentOptions {
+		opt.meterProvider = mp
+		return opt
+	}
+}
+
+// ErrorClass returns a low cardinality name of err suitable for a metric label, "" for a nil error.
+// nolint: cyclop
+func ErrorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, ErrMethodNotSupport):
+		return "not_supported"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrBundleAlreadyKnown):
+		return "already_known"
+	case errors.Is(err, ErrNonceTooLow):
+		return "nonce_too_low"
+	case errors.Is(err, ErrSimulationReverted):
+		return "simulation_reverted"
+	case errors.Is(err, ErrBlockPassed):
+		return "block_passed"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrReplacementUUIDUnknown):
+		return "replacement_uuid_unknown"
+	case errors.Is(err, ErrBuilderUnavailable):
+		return "builder_unavailable"
+	}
+
+	var builderErr *BuilderError
+	if errors.As(err, &builderErr) {
+		return "builder_error"
+	}
+
+	return "other"
+}
+
+type instrumentation struct {
+	senderType BundleSenderType
+	tracer     trace.Tracer
+	// meter is nil when metrics are recorded through pkg/metrics
+	meter metric.Meter
+}
+
+func newInstrumentation(senderType BundleSenderType, opts []InstrumentOption) instrumentation {
+	var options instrumentOptions
+	for i := range opts {
+		if opts[i] == nil {
+			continue
+		}
+		options = opts[i](options)
+	}
+	if options.tracerProvider == nil {
+		options.tracerProvider = otel.GetTracerProvider()
+	}
+
+	ins := instrumentation{
+		senderType: senderType,
+		tracer:     options.tracerProvider.Tracer(tracerName),
+	}
+	if options.meterProvider != nil {
+		ins.meter = options.meterProvider.Meter(tracerName)
+	}
+
+	return ins
+}
+
+func (i instrumentation) recordHistogram(ctx context.Context, name string, value float64, attrs ...attribute.KeyValue) {
+	if i.meter == nil {
+		_ = metrics.RecordFloat64Histogram(ctx, name, value, metric.WithAttributes(attrs...))
+		return
+	}
+	if hist, err := i.meter.Float64Histogram(name); err == nil {
+		hist.Record(ctx, value, metric.WithAttributes(attrs...))
+	}
+}
+
+func (i instrumentation) recordCounter(ctx context.Context, name string, value int64, attrs ...attribute.KeyValue) {
+	if i.meter == nil {
+		_ = metrics.RecordCounter(ctx, name, value, metric.WithAttributes(attrs...))
+		return
+	}
+	if counter, err := i.meter.Int64Counter(name); err == nil {
+		counter.Add(ctx, value, metric.WithAttributes(attrs...))
+	}
+}
+
+// call is one instrumented request, finished by end.
+type call struct {
+	ins    instrumentation
+	ctx    context.Context
+	span   trace.Span
+	method string
+	start  time.Time
+}
+
+// begin starts the span of method, blockNumber and bundleSize are recorded when not zero.
+func (i instrumentation) begin(
+	ctx context.Context, method string, blockNumber uint64, bundleSize int, attrs ...attribute.KeyValue,
+) (context.Context, *call) {
+	attrs = append(attrs, AttrSenderType.String(i.senderType.String()), AttrMethod.String(method))
+	if blockNumber != 0 {
+		attrs = append(attrs, AttrBlockNumber.Int64(int64(blockNumber))) // nolint: gosec
+	}
+	ctx, span := i.tracer.Start(ctx, "mev."+method,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	if bundleSize != 0 {
+		i.recordHistogram(ctx, MetricSenderBundleSize, float64(bundleSize),
+			AttrSenderType.String(i.senderType.String()), AttrMethod.String(method))
+	}
+
+	return ctx, &call{ins: i, ctx: ctx, span: span, method: method, start: time.Now()}
+}
+
+func (c *call) end(bundleHash string, err error) {
+	defer c.span.End()
+
+	result := "ok"
+	labels := []attribute.KeyValue{
+		AttrSenderType.String(c.ins.senderType.String()),
+		AttrMethod.String(c.method),
+	}
+	if err != nil {
+		result = "error"
+		labels = append(labels, AttrErrorClass.String(ErrorClass(err)))
+		c.span.SetAttributes(AttrErrorClass.String(ErrorClass(err)))
+		c.span.RecordError(err)
+		c.span.SetStatus(codes.Error, err.Error())
+	}
+	labels = append(labels, AttrResult.String(result))
+	if bundleHash != "" {
+		c.span.SetAttributes(AttrBundleHash.String(CleanBundleHash(bundleHash)))
+	}
+	c.span.SetAttributes(AttrResult.String(result))
+
+	elapsed := float64(time.Since(c.start)) / float64(time.Millisecond)
+	c.ins.recordHistogram(c.ctx, MetricSenderRequestDuration, elapsed, labels...)
+	c.ins.recordCounter(c.ctx, MetricSenderRequests, 1, labels...)
+}
+
+// InstrumentedSender decorates an IBundleSender with latency, result and bundle size metrics
+// recorded through pkg/metrics or WithMeterProvider, and an OpenTelemetry span per request.
+type InstrumentedSender struct {
+	IBundleSender
+	ins instrumentation
+}
+
+var _ IBundleSender = &InstrumentedSender{}
+
+func NewInstrumentedSender(sender IBundleSender, opts ...InstrumentOption) *InstrumentedSender {
+	return &InstrumentedSender{
+		IBundleSender: sender,
+		ins:           newInstrumentation(sender.GetSenderType(), opts),
+	}
+}
+
+// Health forwards the health of the wrapped sender, so the Broadcaster still skips open circuits.
+func (s *InstrumentedSender) Health() BuilderHealth {
+	if r, ok := s.IBundleSender.(HealthReporter); ok {
+		return r.Health()
+	}
+
+	return BuilderHealth{SenderType: s.GetSenderType(), State: CircuitClosed}
+}
+
+func (s *InstrumentedSender) SendBundle(
+	ctx context.Context,
+	uuid *string,
+	blockNumber uint64,
+	txs ...*types.Transaction,
+) (SendBundleResponse, error) {
+	ctx, c := s.ins.begin(ctx, methodSendBundle, blockNumber, len(txs))
+	resp, err := s.IBundleSender.SendBundle(ctx, uuid, blockNumber, txs...)
+	c.end(resp.Result.BundleHash, err)
+
+	return resp, err
+}
+
+func (s *InstrumentedSender) SendBundleV2(
+	ctx context.Context,
+	req SendBundleV2Request,
+	txs ...*types.Transaction,
+) (SendBundleResponse, error) {
+	var blockNumber uint64
+	if req.BlockNumber != nil {
+		blockNumber = *req.BlockNumber
+	}
+	ctx, c := s.ins.begin(ctx, methodSendBundleV2, blockNumber, len(txs))
+	resp, err := s.IBundleSender.SendBundleV2(ctx, req, txs...)
+	c.end(resp.Result.BundleHash, err)
+
+	return resp, err
+}
+
+func (s *InstrumentedSender) SendBundleHex(
+	ctx context.Context,
+	uuid *string,
+	blockNumber uint64,
+	hexEncodedTxs ...string,
+) (SendBundleResponse, error) {
+	ctx, c := s.ins.begin(ctx, methodSendBundleHex, blockNumber, len(hexEncodedTxs))
+	resp, err := s.IBundleSender.SendBundleHex(ctx, uuid, blockNumber, hexEncodedTxs...)
+	c.end(resp.Result.BundleHash, err)
+
+	return resp, err
+}
+
+func (s *InstrumentedSender) CancelBundle(ctx context.Context, bundleUUID string) error {
+	ctx, c := s.ins.begin(ctx, methodCancelBundle, 0, 0)
+	err := s.IBundleSender.CancelBundle(ctx, bundleUUID)
+	c.end("", err)
+
+	return err
+}
+
+func (s *InstrumentedSender) SendPrivateRawTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) (SendPrivateRawTransactionResponse, error) {
+	ctx, c := s.ins.begin(ctx, methodSendPrivateRawTransaction, 0, 0, AttrTxHash.String(tx.Hash().String()))
+	resp, err := s.IBundleSender.SendPrivateRawTransaction(ctx, tx)
+	c.end("", err)
+
+	return resp, err
+}
+
+func (s *InstrumentedSender) SimulateBundle(
+	ctx context.Context, blockNumber uint64, txs ...*types.Transaction,
+) (SendBundleResponse, error) {
+	ctx, c := s.ins.begin(ctx, methodSimulateBundle, blockNumber, len(txs))
+	resp, err := s.IBundleSender.SimulateBundle(ctx, blockNumber, txs...)
+	c.end(resp.Result.BundleHash, err)
+
+	return resp, err
+}
+
+func (s *InstrumentedSender) GetBundleStats(
+	ctx context.Context, blockNumber uint64, bundleHash common.Hash,
+) (GetBundleStatsResponse, error) {
+	ctx, c := s.ins.begin(ctx, methodGetBundleStats, blockNumber, 0)
+	resp, err := s.IBundleSender.GetBundleStats(ctx, blockNumber, bundleHash)
+	c.end(bundleHash.String(), err)
+
+	return resp, err
+}
+
+func (s *InstrumentedSender) GetUserStats(
+	ctx context.Context,
+	useV2 bool,
+	blockNumber uint64,
+) (map[string]any, error) {
+	ctx, c := s.ins.begin(ctx, methodGetUserStats, blockNumber, 0)
+	resp, err := s.IBundleSender.GetUserStats(ctx, useV2, blockNumber)
+	c.end("", err)
+
+	return resp, err
+}
+
+// InstrumentedBackrunSender is the IBackrunSender counterpart of InstrumentedSender.
+type InstrumentedBackrunSender struct {
+	IBackrunSender
+	ins instrumentation
+}
+
+var _ IBackrunSender = &InstrumentedBackrunSender{}
+
+func NewInstrumentedBackrunSender(sender IBackrunSender, opts ...InstrumentOption) *InstrumentedBackrunSender {
+	return &InstrumentedBackrunSender{
+		IBackrunSender: sender,
+		ins:            newInstrumentation(sender.GetSenderType(), opts),
+	}
+}
+
+func (s *InstrumentedBackrunSender) SendBackrunBundle(
+	ctx context.Context,
+	uuid *string,
+	blockNumber uint64,
+	maxBlockNumber uint64,
+	pendingTxHashes []common.Hash,
+	targetBuilders []string,
+	txs ...*types.Transaction,
+) (SendBundleResponse, error) {
+	ctx, c := s.ins.begin(ctx, methodSendBackrunBundle, blockNumber, len(pendingTxHashes)+len(txs))
+	resp, err := s.IBackrunSender.SendBackrunBundle(
+		ctx, uuid, blockNumber, maxBlockNumber, pendingTxHashes, targetBuilders, txs...)
+	c.end(resp.Result.BundleHash, err)
+
+	return resp, err
+}
+
+func (s *InstrumentedBackrunSender) MevSimulateBundle(
+	ctx context.Context,
+	blockNumber uint64,
+	pendingTxHash common.Hash,
+	tx *types.Transaction,
+) (*mevshare.SimMevBundleResponse, error) {
+	ctx, c := s.ins.begin(ctx, methodMevSimulateBundle, blockNumber, 2) // nolint: mnd
+	resp, err := s.IBackrunSender.MevSimulateBundle(ctx, blockNumber, pendingTxHash, tx)
+	c.end("", err)
+
+	return resp, err
+}
+
+// InstrumentedRawTxSender is the ISendRawTransaction counterpart of InstrumentedSender.
+type InstrumentedRawTxSender struct {
+	ISendRawTransaction
+	ins instrumentation
+}
+
+var _ ISendRawTransaction = &InstrumentedRawTxSender{}
+
+func NewInstrumentedRawTxSender(
+	sender ISendRawTransaction, senderType BundleSenderType, opts ...InstrumentOption,
+) *InstrumentedRawTxSender {
+	return &InstrumentedRawTxSender{
+		ISendRawTransaction: sender,
+		ins:                 newInstrumentation(senderType, opts),
+	}
+}
+
+func (s *InstrumentedRawTxSender) GetSenderType() BundleSenderType {
+	return s.ins.senderType
+}
+
+func (s *InstrumentedRawTxSender) SendRawTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) (SendRawTransactionResponse, error) {
+	ctx, c := s.ins.begin(ctx, methodSendRawTransaction, 0, 0, AttrTxHash.String(tx.Hash().String()))
+	resp, err := s.ISendRawTransaction.SendRawTransaction(ctx, tx)
+	c.end("", err)
+
+	return resp, err
+}
@@ -0,0 +1,124 @@
+package mev_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
+
+// requestCounts returns the MetricSenderRequests counts by method and result.
+func requestCounts(t *testing.T, reader sdkmetric.Reader) map[[2]string]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	counts := make(map[[2]string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != mev.MetricSenderRequests {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			require.True(t, ok)
+			for _, dp := range sum.DataPoints {
+				method, _ := dp.Attributes.Value(mev.AttrMethod)
+				result, _ := dp.Attributes.Value(mev.AttrResult)
+				counts[[2]string{method.AsString(), result.AsString()}] += dp.Value
+			}
+		}
+	}
+
+	return counts
+}
+
+func TestInstrumentedSender(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeTitan)
+	defer relay.Close()
+	relay.Script(mev.EthCallBundleMethod, mevtest.Response{HTTPStatus: http.StatusTooManyRequests})
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeTitan, false)
+	require.NoError(t, err)
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	sender := mev.NewInstrumentedSender(client, mev.WithTracerProvider(tp), mev.WithMeterProvider(mp))
+
+	resp, err := sender.SendBundle(context.Background(), nil, 100, newSignedTx(t, 0), newSignedTx(t, 1))
+	require.NoError(t, err)
+	_, err = sender.SimulateBundle(context.Background(), 100, newSignedTx(t, 0))
+	require.ErrorIs(t, err, mev.ErrRateLimited)
+	_, err = sender.SendBundleV2(context.Background(), mev.SendBundleV2Request{}, newSignedTx(t, 0))
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	require.Equal(t, "mev.SendBundle", spans[0].Name())
+	require.Equal(t, mev.BundleSenderTypeTitan.String(), spanAttr(spans[0], mev.AttrSenderType).AsString())
+	require.Equal(t, int64(100), spanAttr(spans[0], mev.AttrBlockNumber).AsInt64())
+	require.Equal(t, mev.CleanBundleHash(resp.Result.BundleHash), spanAttr(spans[0], mev.AttrBundleHash).AsString())
+	require.Equal(t, "ok", spanAttr(spans[0], mev.AttrResult).AsString())
+
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Equal(t, "rate_limited", spanAttr(spans[1], mev.AttrErrorClass).AsString())
+
+	require.Equal(t, "mev.SendBundleV2", spans[2].Name())
+	require.Equal(t, "SendBundleV2", spanAttr(spans[2], mev.AttrMethod).AsString())
+
+	require.Equal(t, map[[2]string]int64{
+		{"SendBundle", "ok"}:        1,
+		{"SimulateBundle", "error"}: 1,
+		{"SendBundleV2", "ok"}:      1,
+	}, requestCounts(t, reader))
+
+	// a bloXroute client is labelled the same although it calls blxr_submit_bundle
+	blxr := mevtest.NewRelay(mev.BundleSenderTypeBloxroute)
+	defer blxr.Close()
+	reader = sdkmetric.NewManualReader()
+	sender = mev.NewInstrumentedSender(mev.NewBloxrouteClient(blxr.Client(), blxr.URL(), "auth", nil),
+		mev.WithTracerProvider(tp), mev.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	_, err = sender.SendBundle(context.Background(), nil, 100, newSignedTx(t, 0))
+	require.NoError(t, err)
+	require.Len(t, blxr.RequestsFor(mev.BloxrouteSubmitBundleMethod), 1)
+	require.Equal(t, map[[2]string]int64{{"SendBundle", "ok"}: 1}, requestCounts(t, reader))
+}
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: nil, want: ""},
+		{err: fmt.Errorf("wrapped: %w", context.DeadlineExceeded), want: "deadline_exceeded"},
+		{err: mev.ErrCircuitOpen, want: "circuit_open"},
+		{err: &mev.BuilderError{Kind: mev.ErrNonceTooLow}, want: "nonce_too_low"},
+		{err: &mev.BuilderError{HTTPStatus: http.StatusBadRequest}, want: "builder_error"},
+		{err: fmt.Errorf("boom"), want: "other"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, mev.ErrorClass(tt.err))
+	}
+}
@@ -0,0 +1,174 @@
+package mev
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// jsonRPCCodeConditionRejected is returned by sequencers when the condition of a tx does not hold.
+	jsonRPCCodeConditionRejected = -32003
+)
+
+type ISendRawTransactionConditional interface {
+	SendRawTransactionConditional(
+		ctx context.Context,
+		tx *types.Transaction,
+		condition TransactionConditional,
+	) (SendRawTransactionResponse, error)
+}
+
+var _ ISendRawTransactionConditional = &L2Sender{}
+
+// KnownAccount is the expected storage of an account, either its storage root or some slot values.
+type KnownAccount struct {
+	StorageRoot  *common.Hash
+	StorageSlots map[common.Hash]common.Hash
+}
+
+func StorageRootAccount(root common.Hash) KnownAccount {
+	return KnownAccount{StorageRoot: &root}
+}
+
+func StorageSlotsAccount(slots map[common.Hash]common.Hash) KnownAccount {
+	return KnownAccount{StorageSlots: slots}
+}
+
+func (a KnownAccount) MarshalJSON() ([]byte, error) {
+	if a.StorageRoot != nil {
+		return json.Marshal(a.StorageRoot)
+	}
+
+	return json.Marshal(a.StorageSlots)
+}
+
+func (a *KnownAccount) UnmarshalJSON(b []byte) error {
+	var root common.Hash
+	if err := json.Unmarshal(b, &root); err == nil {
+		*a = KnownAccount{StorageRoot: &root}
+		return nil
+	}
+
+	var slots map[common.Hash]common.Hash
+	if err := json.Unmarshal(b, &slots); err != nil {
+		return fmt.Errorf("known account is neither a storage root nor a slot map: %w", err)
+	}
+	*a = KnownAccount{StorageSlots: slots}
+
+	return nil
+}
+
+// TransactionConditional is the condition of eth_sendRawTransactionConditional,
+// the sequencer drops the tx if it does not hold when the tx would be included.
+type TransactionConditional struct {
+	KnownAccounts  map[common.Address]KnownAccount `json:"knownAccounts,omitempty"`
+	BlockNumberMin *hexutil.Uint64                 `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *hexutil.Uint64                 `json:"blockNumberMax,omitempty"`
+	TimestampMin   *hexutil.Uint64                 `json:"timestampMin,omitempty"`
+	TimestampMax   *hexutil.Uint64                 `json:"timestampMax,omitempty"`
+}
+
+// KnownAccountsFromPrestate builds knownAccounts from the storage slots read in a prestate trace,
+// restricted to accounts when given. Accounts without storage in the prestate are skipped.
+func KnownAccountsFromPrestate(
+	prestate *tradingtypes.Prestate, accounts ...common.Address,
+) map[common.Address]KnownAccount {
+	if prestate == nil {
+		return nil
+	}
+
+	wanted := make(map[common.Address]struct{}, len(accounts))
+	for _, addr := range accounts {
+		wanted[addr] = struct{}{}
+	}
+
+	known := make(map[common.Address]KnownAccount)
+	for addr, account := range prestate.Pre {
+		if account == nil || len(account.Storage) == 0 {
+			continue
+		}
+		if _, ok := wanted[addr]; len(wanted) != 0 && !ok {
+			continue
+		}
+		slots := make(map[common.Hash]common.Hash, len(account.Storage))
+		for slot, value := range account.Storage {
+			slots[slot] = value
+		}
+		known[addr] = StorageSlotsAccount(slots)
+	}
+
+	return known
+}
+
+// SendRawTransactionConditional sends tx with eth_sendRawTransactionConditional, a refused condition
+// is returned as a *BuilderError unwrapping to ErrConditionRejected or ErrConditionCostExceeded.
+func (s *L2Sender) SendRawTransactionConditional(
+	ctx context.Context,
+	tx *types.Transaction,
+	condition TransactionConditional,
+) (SendRawTransactionResponse, error) {
+	txBin, err := tx.MarshalBinary()
+	if err != nil {
+		return SendRawTransactionResponse{}, fmt.Errorf("marshal tx binary: %w", err)
+	}
+
+	req := SendRequest{
+		ID:      SendBundleID,
+		JSONRPC: JSONRPC2,
+		Method:  ETHSendRawTransactionConditional,
+		Params:  []any{hexutil.Encode(txBin), condition},
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return SendRawTransactionResponse{}, fmt.Errorf("marshal json error: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return SendRawTransactionResponse{}, fmt.Errorf("new http request error: %w", err)
+	}
+
+	resp, err := doRequest[SendRawTransactionResponse](s.c, httpReq)
+	if err != nil {
+		var builderErr *BuilderError
+		if errors.As(err, &builderErr) {
+			if kind := classifyConditionalError(builderErr.Code, builderErr.Message); kind != nil {
+				builderErr.Kind = kind
+			}
+		}
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// classifyConditionalError recognises condition refusals of op-geth, Base and Arbitrum sequencers.
+func classifyConditionalError(code int, message string) error {
+	msg := strings.ToLower(message)
+	switch {
+	case code == jsonRPCCodeLimitExceeded && strings.Contains(msg, "cost"),
+		strings.Contains(msg, "conditional cost"):
+		return ErrConditionCostExceeded
+	case code == jsonRPCCodeConditionRejected,
+		strings.Contains(msg, "failed conditional"),
+		strings.Contains(msg, "knownaccounts"),
+		strings.Contains(msg, "storage root"),
+		strings.Contains(msg, "storage slot"),
+		strings.Contains(msg, "out of block range"),
+		strings.Contains(msg, "out of time range"):
+		return ErrConditionRejected
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package mev_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestL2Sender_SendRawTransactionConditional(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeL2)
+	defer relay.Close()
+	sender := mev.NewL2ChainSender(relay.Client(), relay.URL(), mev.BundleSenderTypeL2)
+
+	pool := common.HexToAddress("0x01")
+	root := common.HexToHash("0xaa")
+	maxBlock := hexutil.Uint64(120)
+	condition := mev.TransactionConditional{
+		KnownAccounts: map[common.Address]mev.KnownAccount{
+			pool:                        mev.StorageSlotsAccount(map[common.Hash]common.Hash{{1}: {2}}),
+			common.HexToAddress("0x02"): mev.StorageRootAccount(root),
+		},
+		BlockNumberMax: &maxBlock,
+	}
+
+	tx := newSignedTx(t, 0)
+	resp, err := sender.SendRawTransactionConditional(context.Background(), tx, condition)
+	require.NoError(t, err)
+	require.Equal(t, tx.Hash().Hex(), resp.Result)
+
+	reqs := relay.RequestsFor(mev.ETHSendRawTransactionConditional)
+	require.Len(t, reqs, 1)
+	var params []json.RawMessage
+	require.NoError(t, json.Unmarshal(reqs[0].Params, &params))
+	require.JSONEq(t, `{
+		"knownAccounts": {
+			"0x0000000000000000000000000000000000000001": {
+				"0x0100000000000000000000000000000000000000000000000000000000000000":
+				"0x0200000000000000000000000000000000000000000000000000000000000000"
+			},
+			"0x0000000000000000000000000000000000000002":
+			"0x00000000000000000000000000000000000000000000000000000000000000aa"
+		},
+		"blockNumberMax": "0x78"
+	}`, string(params[1]))
+
+	var decoded mev.TransactionConditional
+	require.NoError(t, json.Unmarshal(params[1], &decoded))
+	require.Equal(t, condition, decoded)
+
+	relay.Script(mev.ETHSendRawTransactionConditional, mevtest.Response{
+		Error: &mev.ErrorResponse{Code: -32003, Messange: "Transaction rejected: storage slot value mismatch"},
+	}, mevtest.Response{
+		Error: &mev.ErrorResponse{Code: -32005, Messange: "conditional cost, 1001, exceeded max: 1000"},
+	})
+	_, err = sender.SendRawTransactionConditional(context.Background(), tx, condition)
+	require.ErrorIs(t, err, mev.ErrConditionRejected)
+	_, err = sender.SendRawTransactionConditional(context.Background(), tx, condition)
+	require.ErrorIs(t, err, mev.ErrConditionCostExceeded)
+	require.NotErrorIs(t, err, mev.ErrRateLimited)
+
+	// a revert reason mentioning a condition is not a rejected conditional
+	relay.Script(mev.ETHSendRawTransactionConditional, mevtest.Response{
+		Error: &mev.ErrorResponse{Code: -32000, Messange: "execution reverted: condition not met"},
+	})
+	_, err = sender.SendRawTransactionConditional(context.Background(), tx, condition)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, mev.ErrConditionRejected)
+}
+
+func TestKnownAccountsFromPrestate(t *testing.T) {
+	pool := common.HexToAddress("0x01")
+	token := common.HexToAddress("0x02")
+	prestate := &tradingtypes.Prestate{Pre: tradingtypes.StateMap{
+		pool:                        {Storage: map[common.Hash]common.Hash{{1}: {2}}},
+		token:                       {Storage: map[common.Hash]common.Hash{{3}: {4}}},
+		common.HexToAddress("0x03"): {Nonce: 1},
+	}}
+
+	known := mev.KnownAccountsFromPrestate(prestate)
+	require.Len(t, known, 2)
+	require.Equal(t, map[common.Hash]common.Hash{{1}: {2}}, known[pool].StorageSlots)
+
+	known = mev.KnownAccountsFromPrestate(prestate, token)
+	require.Len(t, known, 1)
+	require.Contains(t, known, token)
+}
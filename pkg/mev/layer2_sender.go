@@ -64,10 +64,5 @@ func (s *L2Sender) SendRawTransaction(
 		return SendRawTransactionResponse{}, err
 	}
 
-	if len(resp.Error.Messange) != 0 {
-		return SendRawTransactionResponse{}, fmt.Errorf("response error, code: [%d], message: [%s]",
-			resp.Error.Code, resp.Error.Messange)
-	}
-
 	return resp, nil
 }
@@ -0,0 +1,267 @@
+package mev
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+)
+
+const defaultSimBlockGasLimit = 36_000_000
+
+// SimBlockContext is the block the bundle is simulated in.
+type SimBlockContext struct {
+	Number uint64
+	Time   uint64
+	// BaseFee defaults to 0, set it to the base fee predicted from the parent header.
+	BaseFee  *big.Int
+	Coinbase common.Address
+	// GasLimit defaults to 36M.
+	GasLimit uint64
+	// BlobBaseFee defaults to 1 wei.
+	BlobBaseFee *big.Int
+	Random      common.Hash
+}
+
+type LocalSimulatorOption func(localSimulatorOptions) localSimulatorOptions
+
+type localSimulatorOptions struct {
+	chainConfig *params.ChainConfig
+	getHash     vm.GetHashFunc
+}
+
+// WithChainConfig sets the chain rules, default is params.MainnetChainConfig.
+func WithChainConfig(cfg *params.ChainConfig) LocalSimulatorOption {
+	return func(opt localSimulatorOptions) localSimulatorOptions {
+		opt.chainConfig = cfg
+		return opt
+	}
+}
+
+// WithBlockHashes serves BLOCKHASH from hashes, unknown blocks return the zero hash.
+func WithBlockHashes(hashes map[uint64]common.Hash) LocalSimulatorOption {
+	return func(opt localSimulatorOptions) localSimulatorOptions {
+		opt.getHash = func(n uint64) common.Hash { return hashes[n] }
+		return opt
+	}
+}
+
+// LocalSimulator executes bundles on an in-memory state, it mirrors eth_callBundle without a node.
+// Accounts missing from the state are empty, so the state must contain every account the bundle touches.
+type LocalSimulator struct {
+	state tradingtypes.StateMap
+	block SimBlockContext
+	opts  localSimulatorOptions
+}
+
+func NewLocalSimulator(
+	stateMap tradingtypes.StateMap, block SimBlockContext, opts ...LocalSimulatorOption,
+) *LocalSimulator {
+	options := localSimulatorOptions{
+		chainConfig: params.MainnetChainConfig,
+		getHash:     func(uint64) common.Hash { return common.Hash{} },
+	}
+	for i := range opts {
+		if opts[i] == nil {
+			continue
+		}
+		options = opts[i](options)
+	}
+	if block.GasLimit == 0 {
+		block.GasLimit = defaultSimBlockGasLimit
+	}
+	if block.BaseFee == nil {
+		// post London rules dereference the base fee
+		block.BaseFee = new(big.Int)
+	}
+	if block.BlobBaseFee == nil {
+		block.BlobBaseFee = big.NewInt(1)
+	}
+
+	return &LocalSimulator{
+		state: stateMap,
+		block: block,
+		opts:  options,
+	}
+}
+
+// NewLocalSimulatorFromPrestate seeds the state with the pre state of a traced pending tx.
+func NewLocalSimulatorFromPrestate(
+	prestate *tradingtypes.Prestate, block SimBlockContext, opts ...LocalSimulatorOption,
+) *LocalSimulator {
+	var stateMap tradingtypes.StateMap
+	if prestate != nil {
+		stateMap = prestate.Pre
+	}
+
+	return NewLocalSimulator(stateMap, block, opts...)
+}
+
+// SimulateBundle executes txs in order on a fresh copy of the state. blockNumber overrides the number of
+// the block context when not 0. Reverted txs are reported in the results like eth_callBundle does,
+// an error is returned only when a tx cannot be included (e.g. bad nonce, insufficient funds).
+func (s *LocalSimulator) SimulateBundle(
+	ctx context.Context, blockNumber uint64, txs ...*types.Transaction,
+) (SendBundleResponse, error) {
+	block := s.block
+	if blockNumber != 0 {
+		block.Number = blockNumber
+	}
+
+	statedb, err := s.newStateDB()
+	if err != nil {
+		return SendBundleResponse{}, err
+	}
+	blockCtx := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     s.opts.getHash,
+		Coinbase:    block.Coinbase,
+		GasLimit:    block.GasLimit,
+		BlockNumber: new(big.Int).SetUint64(block.Number),
+		Time:        block.Time,
+		Difficulty:  new(big.Int),
+		BaseFee:     block.BaseFee,
+		BlobBaseFee: block.BlobBaseFee,
+		Random:      &block.Random,
+	}
+	evm := vm.NewEVM(blockCtx, statedb, s.opts.chainConfig, vm.Config{})
+	signer := types.MakeSigner(s.opts.chainConfig, blockCtx.BlockNumber, block.Time)
+	gasPool := new(core.GasPool).AddGas(block.GasLimit)
+
+	var (
+		results           = make([]SendBundleResults, 0, len(txs))
+		totalGasUsed      uint64
+		totalBlobGasUsed  uint64
+		totalGasFees      = new(big.Int)
+		totalCoinbaseDiff = new(big.Int)
+	)
+	for i, tx := range txs {
+		if err := ctx.Err(); err != nil {
+			return SendBundleResponse{}, err
+		}
+
+		msg, err := core.TransactionToMessage(tx, signer, block.BaseFee)
+		if err != nil {
+			return SendBundleResponse{}, fmt.Errorf("tx %s: %w", tx.Hash(), err)
+		}
+		statedb.SetTxContext(tx.Hash(), i)
+		evm.SetTxContext(core.NewEVMTxContext(msg))
+
+		coinbaseBefore := statedb.GetBalance(block.Coinbase).ToBig()
+		execResult, err := core.ApplyMessage(evm, msg, gasPool)
+		if err != nil {
+			return SendBundleResponse{}, fmt.Errorf("tx %s: %w", tx.Hash(), err)
+		}
+		statedb.Finalise(true)
+		coinbaseDiff := new(big.Int).Sub(statedb.GetBalance(block.Coinbase).ToBig(), coinbaseBefore)
+
+		// the fee cap was checked by ApplyMessage
+		gasPrice, _ := tx.EffectiveGasTip(block.BaseFee)
+		gasFees := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(execResult.UsedGas))
+		result := SendBundleResults{
+			GasUsed:           int(execResult.UsedGas), // nolint: gosec
+			TxHash:            tx.Hash().Hex(),
+			FromAddress:       msg.From.Hex(),
+			GasPrice:          gasPrice.String(),
+			GasFees:           gasFees.String(),
+			CoinbaseDiff:      coinbaseDiff.String(),
+			EthSentToCoinbase: new(big.Int).Sub(coinbaseDiff, gasFees).String(),
+			Logs:              statedb.GetLogs(tx.Hash(), block.Number, common.Hash{}, block.Time),
+			BlobGasUsed:       int(tx.BlobGas()), // nolint: gosec
+		}
+		if tx.To() != nil {
+			result.ToAddress = tx.To().Hex()
+		}
+		if execResult.Failed() {
+			result.Error = execResult.Err.Error()
+			result.Revert = hexutil.Encode(execResult.Revert())
+		} else {
+			result.Value = hexutil.Encode(execResult.Return())
+		}
+		results = append(results, result)
+
+		totalGasUsed += execResult.UsedGas
+		totalBlobGasUsed += tx.BlobGas()
+		totalGasFees.Add(totalGasFees, gasFees)
+		totalCoinbaseDiff.Add(totalCoinbaseDiff, coinbaseDiff)
+	}
+
+	bundleGasPrice := new(big.Int)
+	if totalGasUsed != 0 {
+		bundleGasPrice.Div(totalCoinbaseDiff, new(big.Int).SetUint64(totalGasUsed))
+	}
+	var stateBlockNumber uint64
+	if block.Number != 0 {
+		stateBlockNumber = block.Number - 1
+	}
+
+	return SendBundleResponse{
+		Jsonrpc: JSONRPC2,
+		ID:      SendBundleID,
+		Result: SendBundleResult{
+			BundleGasPrice:    bundleGasPrice.String(),
+			BundleHash:        bundleHash(txs).Hex(),
+			CoinbaseDiff:      totalCoinbaseDiff.String(),
+			EthSentToCoinbase: new(big.Int).Sub(totalCoinbaseDiff, totalGasFees).String(),
+			GasFees:           totalGasFees.String(),
+			Results:           results,
+			StateBlockNumber:  int(stateBlockNumber), // nolint: gosec
+			TotalGasUsed:      int(totalGasUsed),     // nolint: gosec
+			TotalBlobGasUsed:  int(totalBlobGasUsed), // nolint: gosec
+		},
+	}, nil
+}
+
+func (s *LocalSimulator) newStateDB() (*state.StateDB, error) {
+	db := state.NewDatabase(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil), nil)
+	statedb, err := state.New(types.EmptyRootHash, db)
+	if err != nil {
+		return nil, fmt.Errorf("new state db: %w", err)
+	}
+	for addr, account := range s.state {
+		if account == nil {
+			continue
+		}
+		if account.Balance != nil {
+			balance, overflow := uint256.FromBig(account.Balance)
+			if overflow || account.Balance.Sign() < 0 {
+				return nil, fmt.Errorf("invalid balance %s of %s", account.Balance, addr)
+			}
+			statedb.SetBalance(addr, balance, tracing.BalanceChangeUnspecified)
+		}
+		statedb.SetNonce(addr, account.Nonce, tracing.NonceChangeUnspecified)
+		if len(account.Code) != 0 {
+			statedb.SetCode(addr, account.Code, tracing.CodeChangeUnspecified)
+		}
+		for key, value := range account.Storage {
+			statedb.SetState(addr, key, value)
+		}
+	}
+	statedb.Finalise(true)
+
+	return statedb, nil
+}
+
+// bundleHash is the keccak of the concatenated tx hashes, as returned by eth_callBundle.
+func bundleHash(txs []*types.Transaction) common.Hash {
+	hashes := make([]byte, 0, len(txs)*common.HashLength)
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+
+	return crypto.Keccak256Hash(hashes)
+}
@@ -0,0 +1,112 @@
+package mev_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalSimulator(t *testing.T) {
+	var (
+		key, _    = crypto.GenerateKey()
+		sender    = crypto.PubkeyToAddress(key.PublicKey)
+		coinbase  = common.HexToAddress("0xc0ffee")
+		payer     = common.HexToAddress("0xaa")
+		reverter  = common.HexToAddress("0xbb")
+		baseFee   = big.NewInt(10)
+		oneEther  = big.NewInt(1e18)
+		blockTime = uint64(1_750_000_000)
+	)
+	prestate := &tradingtypes.Prestate{
+		Pre: tradingtypes.StateMap{
+			sender: {Balance: oneEther, Nonce: 5},
+			// LOG0(0, 0); SELFDESTRUCT(COINBASE)
+			payer: {Balance: big.NewInt(1000), Code: common.FromHex("0x60006000a041ff")},
+			// REVERT(0, 0)
+			reverter: {Code: common.FromHex("0x60006000fd")},
+		},
+	}
+	sim := mev.NewLocalSimulatorFromPrestate(prestate, mev.SimBlockContext{
+		Number:   22_700_000,
+		Time:     blockTime,
+		BaseFee:  baseFee,
+		Coinbase: coinbase,
+	})
+
+	txs := []*types.Transaction{
+		newCallTx(t, key, 5, payer),
+		newCallTx(t, key, 6, reverter),
+	}
+	resp, err := sim.SimulateBundle(context.Background(), 0, txs...)
+	require.NoError(t, err)
+
+	result := resp.Result
+	require.Len(t, result.Results, 2)
+	require.Equal(t, 22_699_999, result.StateBlockNumber)
+
+	paid := result.Results[0]
+	require.Equal(t, txs[0].Hash().Hex(), paid.TxHash)
+	require.Equal(t, sender.Hex(), paid.FromAddress)
+	require.Empty(t, paid.Error)
+	require.Len(t, paid.Logs, 1)
+	require.Equal(t, payer, paid.Logs[0].Address)
+	require.Equal(t, "2", paid.GasPrice)
+	require.Equal(t, "1000", paid.EthSentToCoinbase)
+
+	reverted := result.Results[1]
+	require.NotEmpty(t, reverted.Error)
+	require.Equal(t, "0x", reverted.Revert)
+
+	gasUsed := paid.GasUsed + reverted.GasUsed
+	require.Equal(t, gasUsed, result.TotalGasUsed)
+	require.Equal(t, big.NewInt(int64(2*gasUsed)).String(), result.GasFees)
+	require.Equal(t, big.NewInt(int64(2*gasUsed+1000)).String(), result.CoinbaseDiff)
+	require.Equal(t, "1000", result.EthSentToCoinbase)
+
+	// the state is not shared between simulations
+	_, err = sim.SimulateBundle(context.Background(), 0, txs...)
+	require.NoError(t, err)
+
+	// a tx that can not be included fails the bundle
+	_, err = sim.SimulateBundle(context.Background(), 0, newCallTx(t, key, 4, payer))
+	require.Error(t, err)
+}
+
+func TestLocalSimulator_NoBaseFee(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	sim := mev.NewLocalSimulator(tradingtypes.StateMap{
+		sender: {Balance: big.NewInt(1e18)},
+	}, mev.SimBlockContext{Number: 22_700_000, Time: 1_750_000_000})
+
+	resp, err := sim.SimulateBundle(context.Background(), 0, newCallTx(t, key, 0, common.HexToAddress("0xaa")))
+	require.NoError(t, err)
+	require.Len(t, resp.Result.Results, 1)
+	require.Empty(t, resp.Result.Results[0].Error)
+	require.Equal(t, "2", resp.Result.Results[0].GasPrice)
+}
+
+func newCallTx(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, to common.Address) *types.Transaction {
+	t.Helper()
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     nonce,
+		To:        &to,
+		Gas:       100_000,
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(100),
+		Data:      hexutil.MustDecode("0x01"),
+	})
+	require.NoError(t, err)
+
+	return tx
+}
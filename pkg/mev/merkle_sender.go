@@ -83,11 +83,6 @@ func (b MerkleClient) SendBackrunBundle(
 		return SendBundleResponse{}, err
 	}
 
-	if len(resp.Error.Messange) != 0 {
-		return SendBundleResponse{}, fmt.Errorf("response error, code: [%d], message: [%s]",
-			resp.Error.Code, resp.Error.Messange)
-	}
-
 	return SendBundleResponse{
 		Result: SendBundleResult{
 			BundleGasPrice:    "",
@@ -0,0 +1,272 @@
+package mev
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/flashbots/mev-share-node/mevshare"
+)
+
+// MevBundleVersionV01 is the mev_sendBundle schema version supported by Client.
+const MevBundleVersionV01 = "v0.1"
+
+// MevBundle builds a mev_sendBundle v0.1 request.
+// https://docs.flashbots.net/flashbots-auction/advanced/rpc-endpoint#mev_sendbundle
+type MevBundle struct {
+	Args   mevshare.SendMevBundleArgs
+	Errors []error // check when building bundle
+}
+
+func NewMevBundle(blockNumber, maxBlockNumber uint64) *MevBundle {
+	return &MevBundle{
+		Args: mevshare.SendMevBundleArgs{
+			Version: MevBundleVersionV01,
+			Inclusion: mevshare.MevBundleInclusion{
+				BlockNumber: hexutil.Uint64(blockNumber),
+				MaxBlock:    hexutil.Uint64(maxBlockNumber),
+			},
+		},
+	}
+}
+
+// AddHashes appends pending transactions matched by hash, they are never allowed to revert.
+func (b *MevBundle) AddHashes(txHashes ...common.Hash) *MevBundle {
+	for i := range txHashes {
+		b.Args.Body = append(b.Args.Body, mevshare.MevBundleBody{Hash: &txHashes[i]})
+	}
+
+	return b
+}
+
+func (b *MevBundle) AddTxs(canRevert bool, txs ...*types.Transaction) *MevBundle {
+	for _, tx := range txs {
+		if err := validateBundleTx(tx); err != nil {
+			b.Errors = append(b.Errors, err)
+			continue
+		}
+		txBin, err := tx.MarshalBinary()
+		if err != nil {
+			b.Errors = append(b.Errors, fmt.Errorf("marshal tx: %w", err))
+			continue
+		}
+		raw := hexutil.Bytes(txBin)
+		b.Args.Body = append(b.Args.Body, mevshare.MevBundleBody{Tx: &raw, CanRevert: canRevert})
+	}
+
+	return b
+}
+
+// AddBundle appends a nested bundle, its inclusion is ignored by the relay in favour of the outer one.
+func (b *MevBundle) AddBundle(nested *MevBundle, canRevert bool) *MevBundle {
+	b.Errors = append(b.Errors, nested.Errors...)
+	args := nested.Args
+	b.Args.Body = append(b.Args.Body, mevshare.MevBundleBody{Bundle: &args, CanRevert: canRevert})
+
+	return b
+}
+
+// SetRefund gives percent of the bundle profit to the sender of the body at bodyIdx.
+func (b *MevBundle) SetRefund(bodyIdx, percent int) *MevBundle {
+	b.Args.Validity.Refund = append(b.Args.Validity.Refund, mevshare.RefundConstraint{
+		BodyIdx: bodyIdx,
+		Percent: percent,
+	})
+
+	return b
+}
+
+// AddRefundConfig splits the refund of this bundle between addresses, the percents must sum to 100.
+func (b *MevBundle) AddRefundConfig(address common.Address, percent int) *MevBundle {
+	b.Args.Validity.RefundConfig = append(b.Args.Validity.RefundConfig, mevshare.RefundConfig{
+		Address: address,
+		Percent: percent,
+	})
+
+	return b
+}
+
+func (b *MevBundle) privacy() *mevshare.MevBundlePrivacy {
+	if b.Args.Privacy == nil {
+		b.Args.Privacy = &mevshare.MevBundlePrivacy{}
+	}
+
+	return b.Args.Privacy
+}
+
+// SetHints sets the privacy hints shared with searchers, e.g. mevshare.HintCallData|mevshare.HintLogs.
+func (b *MevBundle) SetHints(hints mevshare.HintIntent) *MevBundle {
+	b.privacy().Hints = hints
+	return b
+}
+
+// SetBuilders sets the builders the bundle is shared with, see FlashbotBuilderRegistration*.
+func (b *MevBundle) SetBuilders(builders ...string) *MevBundle {
+	b.privacy().Builders = builders
+	return b
+}
+
+func (b *MevBundle) SetReplacementUUID(uuid string) *MevBundle {
+	b.Args.ReplacementUUID = uuid
+	return b
+}
+
+func (b *MevBundle) Err() error {
+	if len(b.Errors) == 0 {
+		return nil
+	}
+
+	return errors.Join(b.Errors...)
+}
+
+type MevSimBundleResponse struct {
+	Jsonrpc string                        `json:"jsonrpc,omitempty"`
+	ID      int                           `json:"id,omitempty"`
+	Result  mevshare.SimMevBundleResponse `json:"result,omitempty"`
+	Error   ErrorResponse                 `json:"error,omitempty"`
+}
+
+func (r MevSimBundleResponse) rpcError() ErrorResponse { return r.Error }
+
+// MevSendBundle sends a mev_sendBundle request, the version defaults to MevBundleVersionV01.
+func (s *Client) MevSendBundle(ctx context.Context, bundle *MevBundle) (SendBundleResponse, error) {
+	if err := bundle.Err(); err != nil {
+		return SendBundleResponse{}, err
+	}
+	args := bundle.Args
+	if args.Version == "" {
+		args.Version = MevBundleVersionV01
+	}
+
+	httpReq, headers, err := s.newSignedRequest(ctx, MevSendBundleMethod, args)
+	if err != nil {
+		return SendBundleResponse{}, err
+	}
+
+	return doRequest[SendBundleResponse](s.c, httpReq, headers...)
+}
+
+// MevSimBundle simulates a bundle with mev_simBundle, aux overrides the simulated block header when not nil.
+// A failed simulation returns the result along with an ErrSimulationReverted error.
+func (s *Client) MevSimBundle(
+	ctx context.Context, bundle *MevBundle, aux *mevshare.SimMevBundleAuxArgs,
+) (*mevshare.SimMevBundleResponse, error) {
+	if err := bundle.Err(); err != nil {
+		return nil, err
+	}
+	args := bundle.Args
+	if args.Version == "" {
+		args.Version = MevBundleVersionV01
+	}
+
+	params := []any{args}
+	if aux != nil {
+		params = append(params, aux)
+	}
+	httpReq, headers, err := s.newSignedRequest(ctx, MevSimBundleMethod, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := doRequest[MevSimBundleResponse](s.c, httpReq, headers...)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Result.Success {
+		return &resp.Result, &BuilderError{
+			Kind:       ErrSimulationReverted,
+			Message:    resp.Result.Error,
+			HTTPStatus: http.StatusOK,
+		}
+	}
+
+	return &resp.Result, nil
+}
+
+// SendBackrunBundle sends the pending txs followed by txs with mev_sendBundle,
+// targetBuilders defaults to every builder registered in MEV-Share.
+func (s *Client) SendBackrunBundle(
+	ctx context.Context,
+	uuid *string,
+	blockNumber uint64,
+	maxBlockNumber uint64,
+	pendingTxHashes []common.Hash,
+	targetBuilders []string,
+	txs ...*types.Transaction,
+) (SendBundleResponse, error) {
+	if blockNumber > maxBlockNumber {
+		return SendBundleResponse{}, ErrInvalidMaxBlock
+	}
+	if len(targetBuilders) == 0 {
+		targetBuilders = defaultMevShareBuilders
+	}
+
+	bundle := NewMevBundle(blockNumber, maxBlockNumber).
+		AddHashes(pendingTxHashes...).
+		AddTxs(false, txs...).
+		SetBuilders(targetBuilders...)
+	if uuid != nil {
+		bundle.SetReplacementUUID(*uuid)
+	}
+
+	return s.MevSendBundle(ctx, bundle)
+}
+
+func (s *Client) MevSimulateBundle(
+	ctx context.Context,
+	blockNumber uint64,
+	pendingTxHash common.Hash,
+	tx *types.Transaction,
+) (*mevshare.SimMevBundleResponse, error) {
+	bundle := NewMevBundle(blockNumber, blockNumber).AddHashes(pendingTxHash).AddTxs(false, tx)
+
+	return s.MevSimBundle(ctx, bundle, nil)
+}
+
+func (s *Client) newSignedRequest(
+	ctx context.Context, method string, params ...any,
+) (*http.Request, [][2]string, error) {
+	req := SendRequest{
+		ID:      SendBundleID,
+		JSONRPC: JSONRPC2,
+		Method:  method,
+		Params:  params,
+	}
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal json error: %w", err)
+	}
+
+	var headers [][2]string
+	if s.flashbotKey != nil {
+		signature, err := requestSignature(s.flashbotKey, reqBody)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sign flashbot request error: %w", err)
+		}
+		headers = append(headers, [2]string{XFlashbotSignatureHeader, signature})
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, nil, fmt.Errorf("new http request error: %w", err)
+	}
+
+	return httpReq, headers, nil
+}
+
+var defaultMevShareBuilders = []string{ // nolint: gochecknoglobals
+	FlashbotBuilderRegistrationFlashbot,
+	FlashbotBuilderRegistrationBeaverBuild,
+	FlashbotBuilderRegistrationTitan,
+	FlashbotBuilderRegistrationRsync,
+	FlashbotBuilderRegistrationBobaBuilder,
+	FlashbotBuilderRegistrationBuilder0x69,
+	FlashbotBuilderRegistrationBTCS,
+	FlashbotBuilderRegistrationPenguinBuild,
+}
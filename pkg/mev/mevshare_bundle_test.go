@@ -0,0 +1,97 @@
+package mev_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/flashbots/mev-share-node/mevshare"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_MevSendBundle(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeMevShare, mevtest.WithRequireSignature())
+	defer relay.Close()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	client, err := mev.NewClient(relay.Client(), relay.URL(), key, mev.BundleSenderTypeMevShare, false)
+	require.NoError(t, err)
+
+	pending := common.HexToHash("0x01")
+	refund := common.HexToAddress("0x02")
+	nested := mev.NewMevBundle(10, 10).AddTxs(true, newSignedTx(t, 1))
+	bundle := mev.NewMevBundle(10, 12).
+		AddHashes(pending).
+		AddTxs(false, newSignedTx(t, 0)).
+		AddBundle(nested, true).
+		SetRefund(0, 90).
+		AddRefundConfig(refund, 100).
+		SetHints(mevshare.HintCallData | mevshare.HintTxHash).
+		SetBuilders(mev.FlashbotBuilderRegistrationFlashbot)
+
+	resp, err := client.MevSendBundle(context.Background(), bundle)
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Result.BundleHash)
+
+	requests := relay.RequestsFor(mev.MevSendBundleMethod)
+	require.Len(t, requests, 1)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), requests[0].Signer)
+
+	var params []mevshare.SendMevBundleArgs
+	require.NoError(t, json.Unmarshal(requests[0].Params, &params))
+	args := params[0]
+	require.Equal(t, mev.MevBundleVersionV01, args.Version)
+	require.EqualValues(t, 10, args.Inclusion.BlockNumber)
+	require.EqualValues(t, 12, args.Inclusion.MaxBlock)
+	require.Len(t, args.Body, 3)
+	require.Equal(t, pending, *args.Body[0].Hash)
+	require.NotNil(t, args.Body[1].Tx)
+	require.True(t, args.Body[2].CanRevert)
+	require.Len(t, args.Body[2].Bundle.Body, 1)
+	require.True(t, args.Body[2].Bundle.Body[0].CanRevert)
+	require.Equal(t, []mevshare.RefundConstraint{{BodyIdx: 0, Percent: 90}}, args.Validity.Refund)
+	require.Equal(t, []mevshare.RefundConfig{{Address: refund, Percent: 100}}, args.Validity.RefundConfig)
+	require.True(t, args.Privacy.Hints.HasHint(mevshare.HintCallData))
+	require.True(t, args.Privacy.Hints.HasHint(mevshare.HintTxHash))
+	require.False(t, args.Privacy.Hints.HasHint(mevshare.HintLogs))
+	require.Equal(t, []string{mev.FlashbotBuilderRegistrationFlashbot}, args.Privacy.Builders)
+
+	// as an IBackrunSender
+	var backrunSender mev.IBackrunSender = client
+	_, err = backrunSender.SendBackrunBundle(context.Background(), nil, 10, 11, []common.Hash{pending}, nil,
+		newSignedTx(t, 0))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(relay.RequestsFor(mev.MevSendBundleMethod)[1].Params, &params))
+	require.Equal(t, pending, *params[0].Body[0].Hash)
+	require.Contains(t, params[0].Privacy.Builders, mev.FlashbotBuilderRegistrationTitan)
+}
+
+func TestClient_MevSimBundle(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeMevShare)
+	defer relay.Close()
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeMevShare, false)
+	require.NoError(t, err)
+
+	res, err := client.MevSimulateBundle(context.Background(), 10, common.HexToHash("0x01"), newSignedTx(t, 0))
+	require.NoError(t, err)
+	require.True(t, res.Success)
+
+	relay.Script(mev.MevSimBundleMethod, mevtest.Response{Result: mevshare.SimMevBundleResponse{
+		Success: false,
+		Error:   "tx reverted",
+		GasUsed: 21000,
+	}})
+	res, err = client.MevSimBundle(context.Background(), mev.NewMevBundle(10, 10).AddTxs(false, newSignedTx(t, 0)),
+		&mevshare.SimMevBundleAuxArgs{})
+	require.ErrorIs(t, err, mev.ErrSimulationReverted)
+	require.NotNil(t, res)
+	require.EqualValues(t, 21000, res.GasUsed)
+
+	var params []json.RawMessage
+	require.NoError(t, json.Unmarshal(relay.RequestsFor(mev.MevSimBundleMethod)[1].Params, &params))
+	require.Len(t, params, 2)
+}
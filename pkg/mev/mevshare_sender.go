@@ -87,16 +87,7 @@ func (m FlashbotMevShareSender) SendBackrunBundle(
 	}
 	if len(targetBuilders) == 0 {
 		req.Privacy = &mevshare.MevBundlePrivacy{
-			Builders: []string{
-				FlashbotBuilderRegistrationFlashbot,
-				FlashbotBuilderRegistrationBeaverBuild,
-				FlashbotBuilderRegistrationTitan,
-				FlashbotBuilderRegistrationRsync,
-				FlashbotBuilderRegistrationBobaBuilder,
-				FlashbotBuilderRegistrationBuilder0x69,
-				FlashbotBuilderRegistrationBTCS,
-				FlashbotBuilderRegistrationPenguinBuild,
-			},
+			Builders: defaultMevShareBuilders,
 		}
 	}
 
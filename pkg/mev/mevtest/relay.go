@@ -0,0 +1,335 @@
+// Package mevtest provides an in-process fake builder relay for testing mev senders.
+package mevtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/flashbots/mev-share-node/mevshare"
+)
+
+const (
+	// TitanCancelResult is the result titan returns for a successful eth_cancelBundle.
+	TitanCancelResult = 200
+
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+)
+
+// Request is a JSON-RPC request received by the relay.
+type Request struct {
+	ID     json.RawMessage
+	Method string
+	Params json.RawMessage
+	Header http.Header
+	Body   []byte
+	// Signer is the address recovered from the X-Flashbots-Signature header, zero if absent or invalid.
+	Signer     common.Address
+	ReceivedAt time.Time
+}
+
+// Response is a scripted relay response.
+// Body, when set, is written as is instead of the JSON-RPC envelope built from Result and Error.
+type Response struct {
+	Result     any
+	Error      *mev.ErrorResponse
+	Body       []byte
+	HTTPStatus int
+	Delay      time.Duration
+}
+
+type Option func(relayOptions) relayOptions
+
+type relayOptions struct {
+	requireSignature bool
+	authorization    string
+}
+
+// WithRequireSignature rejects requests without a valid X-Flashbots-Signature header.
+func WithRequireSignature() Option {
+	return func(opt relayOptions) relayOptions {
+		opt.requireSignature = true
+		return opt
+	}
+}
+
+// WithAuthorization rejects requests whose Authorization header does not equal auth.
+func WithAuthorization(auth string) Option {
+	return func(opt relayOptions) relayOptions {
+		opt.authorization = auth
+		return opt
+	}
+}
+
+// Relay is a fake builder relay, flavour selects the builder specific default responses.
+type Relay struct {
+	server  *httptest.Server
+	flavour mev.BundleSenderType
+	opts    relayOptions
+
+	mu       sync.Mutex
+	requests []Request
+	scripts  map[string][]Response
+}
+
+func NewRelay(flavour mev.BundleSenderType, opts ...Option) *Relay {
+	var options relayOptions
+	for i := range opts {
+		if opts[i] == nil {
+			continue
+		}
+		options = opts[i](options)
+	}
+
+	r := &Relay{
+		flavour: flavour,
+		opts:    options,
+		scripts: make(map[string][]Response),
+	}
+	r.server = httptest.NewServer(r)
+
+	return r
+}
+
+func (r *Relay) URL() string {
+	return r.server.URL
+}
+
+func (r *Relay) Client() *http.Client {
+	return r.server.Client()
+}
+
+func (r *Relay) Close() {
+	r.server.Close()
+}
+
+// Script queues responses for method, they are consumed in order before falling back to the defaults.
+func (r *Relay) Script(method string, responses ...Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scripts[method] = append(r.scripts[method], responses...)
+}
+
+// Requests returns every request received so far.
+func (r *Relay) Requests() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Request(nil), r.requests...)
+}
+
+// RequestsFor returns the received requests of method.
+func (r *Relay) RequestsFor(method string) []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []Request
+	for _, req := range r.requests {
+		if req.Method == method {
+			out = append(out, req)
+		}
+	}
+
+	return out
+}
+
+func (r *Relay) ServeHTTP(w http.ResponseWriter, httpReq *http.Request) {
+	body, err := io.ReadAll(httpReq.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var rpcReq struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(body, &rpcReq); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	signer, sigErr := mev.VerifyRequestSignature(httpReq.Header.Get(mev.XFlashbotSignatureHeader), body)
+	r.record(Request{
+		ID:         rpcReq.ID,
+		Method:     rpcReq.Method,
+		Params:     rpcReq.Params,
+		Header:     httpReq.Header.Clone(),
+		Body:       body,
+		Signer:     signer,
+		ReceivedAt: time.Now(),
+	})
+
+	switch {
+	case r.opts.requireSignature && sigErr != nil:
+		writeResponse(w, rpcReq.ID, Response{
+			HTTPStatus: http.StatusForbidden,
+			Error:      &mev.ErrorResponse{Code: codeInvalidRequest, Messange: sigErr.Error()},
+		})
+		return
+	case r.opts.authorization != "" && httpReq.Header.Get("Authorization") != r.opts.authorization:
+		writeResponse(w, rpcReq.ID, Response{
+			HTTPStatus: http.StatusUnauthorized,
+			Error:      &mev.ErrorResponse{Code: codeInvalidRequest, Messange: "invalid authorization"},
+		})
+		return
+	}
+
+	resp := r.next(rpcReq.Method, rpcReq.Params)
+	if resp.Delay > 0 {
+		select {
+		case <-httpReq.Context().Done():
+			return
+		case <-time.After(resp.Delay):
+		}
+	}
+	writeResponse(w, rpcReq.ID, resp)
+}
+
+func (r *Relay) record(req Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+}
+
+func (r *Relay) next(method string, params json.RawMessage) Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if queue := r.scripts[method]; len(queue) != 0 {
+		r.scripts[method] = queue[1:]
+		return queue[0]
+	}
+
+	return r.defaultResponse(method, params)
+}
+
+// nolint: cyclop
+func (r *Relay) defaultResponse(method string, params json.RawMessage) Response {
+	bundleHash := crypto.Keccak256Hash(params)
+
+	switch method {
+	case mev.ETHSendBundleMethod:
+		if r.flavour == mev.BundleSenderTypeBlink || r.flavour == mev.BundleSenderTypeMerkle {
+			return Response{Result: bundleHash.Hex()}
+		}
+		return Response{Result: mev.SendBundleResult{BundleHash: bundleHash.Hex()}}
+	case mev.EthCallBundleMethod, mev.BloxrouteSimulationBundleMethod:
+		return Response{Result: mev.SendBundleResult{BundleHash: bundleHash.Hex(), StateBlockNumber: 1}}
+	case mev.ETHCancelBundleMethod:
+		switch r.flavour {
+		case mev.BundleSenderTypeTitan:
+			return Response{Result: TitanCancelResult}
+		case mev.BundleSenderTypeFlashbot:
+			return Response{Result: []string{}}
+		default:
+			return Response{}
+		}
+	case mev.MevSendBundleMethod, mev.BloxrouteSubmitBundleMethod:
+		return Response{Result: map[string]string{"bundleHash": bundleHash.Hex()}}
+	case mev.MevSimBundleMethod:
+		return Response{Result: mevshare.SimMevBundleResponse{Success: true, StateBlock: 1}}
+	case mev.FlashbotGetBundleStatsMethod:
+		return Response{Result: mev.GetBundleStatsResult{
+			IsHighPriority: true,
+			IsSimulated:    true,
+			SimulatedAt:    time.Now().UTC(),
+			ReceivedAt:     time.Now().UTC(),
+		}}
+	case mev.FlashbotGetUserStats:
+		return Response{Result: map[string]any{"is_high_priority": true}}
+	case mev.FlashbotGetUserStatsV2, mev.TitanGetUserStats:
+		return Response{Result: map[string]any{"isHighPriority": true}}
+	case mev.BloxrouteBundleTraceMethod:
+		var p mev.BloxrouteBundleTraceParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return Response{Error: &mev.ErrorResponse{Code: codeInvalidRequest, Messange: err.Error()}}
+		}
+		return Response{Result: mev.BloxrouteBundleTrace{
+			BundleHash:  p.BundleHash,
+			ReceivedAt:  time.Now().UTC(),
+			IsSimulated: true,
+		}}
+	case mev.ETHSendPrivateRawTransaction, mev.ETHSendPrivateTransaction, mev.ETHSendRawTransaction,
+		mev.ETHSendRawTransactionConditional:
+		txHash, err := rawTxHash(params)
+		if err != nil {
+			return Response{Error: &mev.ErrorResponse{Code: codeInvalidRequest, Messange: err.Error()}}
+		}
+		return Response{Result: txHash.Hex()}
+	default:
+		return Response{Error: &mev.ErrorResponse{
+			Code:     codeMethodNotFound,
+			Messange: fmt.Sprintf("the method %s does not exist/is not available", method),
+		}}
+	}
+}
+
+func writeResponse(w http.ResponseWriter, id json.RawMessage, resp Response) {
+	status := resp.HTTPStatus
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	body := resp.Body
+	if body == nil {
+		envelope := map[string]any{
+			"jsonrpc": mev.JSONRPC2,
+			"id":      id,
+		}
+		if resp.Error != nil {
+			envelope["error"] = resp.Error
+		} else {
+			envelope["result"] = resp.Result
+		}
+
+		var err error
+		if body, err = json.Marshal(envelope); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func rawTxHash(params json.RawMessage) (common.Hash, error) {
+	var args []json.RawMessage
+	if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+		return common.Hash{}, fmt.Errorf("invalid raw transaction params")
+	}
+	var raw string
+	if err := json.Unmarshal(args[0], &raw); err != nil {
+		// eth_sendPrivateTransaction
+		var obj struct {
+			Tx string `json:"tx"`
+		}
+		if err := json.Unmarshal(args[0], &obj); err != nil {
+			return common.Hash{}, fmt.Errorf("invalid raw transaction params")
+		}
+		raw = obj.Tx
+	}
+	txBin, err := hexutil.Decode(raw)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("decode raw transaction: %w", err)
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(txBin); err != nil {
+		return common.Hash{}, fmt.Errorf("unmarshal raw transaction: %w", err)
+	}
+
+	return tx.Hash(), nil
+}
@@ -0,0 +1,109 @@
+package mevtest_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func newTx(t *testing.T) *types.Transaction {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		To:        &to,
+		Gas:       21000,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+	})
+	require.NoError(t, err)
+
+	return tx
+}
+
+func TestRelay_SendBundleSigned(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeFlashbot, mevtest.WithRequireSignature())
+	defer relay.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	client, err := mev.NewClient(relay.Client(), relay.URL(), key, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+
+	resp, err := client.SendBundle(context.Background(), nil, 100, newTx(t))
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Result.BundleHash)
+
+	reqs := relay.RequestsFor(mev.ETHSendBundleMethod)
+	require.Len(t, reqs, 1)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), reqs[0].Signer)
+
+	unsigned, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	_, err = unsigned.SendBundle(context.Background(), nil, 100, newTx(t))
+	require.Error(t, err)
+}
+
+func TestRelay_Flavours(t *testing.T) {
+	blink := mevtest.NewRelay(mev.BundleSenderTypeBlink)
+	defer blink.Close()
+	blinkClient, err := mev.NewClient(blink.Client(), blink.URL(), nil, mev.BundleSenderTypeBlink, false)
+	require.NoError(t, err)
+	resp, err := blinkClient.SendBundle(context.Background(), nil, 100, newTx(t))
+	require.NoError(t, err)
+	require.Len(t, resp.Result.BundleHash, 66)
+
+	titan := mevtest.NewRelay(mev.BundleSenderTypeTitan)
+	defer titan.Close()
+	titanClient, err := mev.NewClient(titan.Client(), titan.URL(), nil, mev.BundleSenderTypeTitan, false)
+	require.NoError(t, err)
+	require.NoError(t, titanClient.CancelBundle(context.Background(), "uuid"))
+	require.Len(t, titan.RequestsFor(mev.ETHCancelBundleMethod), 1)
+
+	blxr := mevtest.NewRelay(mev.BundleSenderTypeBloxroute, mevtest.WithAuthorization("secret"))
+	defer blxr.Close()
+	blxrResp, err := mev.NewBloxrouteClient(blxr.Client(), blxr.URL(), "secret", nil, mev.BuilderAll).
+		SendBundle(context.Background(), nil, 100, newTx(t))
+	require.NoError(t, err)
+	require.NotEmpty(t, blxrResp.Result.BundleHash)
+	_, err = mev.NewBloxrouteClient(blxr.Client(), blxr.URL(), "wrong", nil).
+		SendBundle(context.Background(), nil, 100, newTx(t))
+	require.Error(t, err)
+}
+
+func TestRelay_Script(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeBeaver)
+	defer relay.Close()
+	relay.Script(mev.ETHSendBundleMethod,
+		mevtest.Response{Error: &mev.ErrorResponse{Code: -32000, Messange: "bundle already known"}},
+		mevtest.Response{Delay: time.Second},
+	)
+
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeBeaver, true,
+		mev.WithSendPrivateRaw())
+	require.NoError(t, err)
+
+	_, err = client.SendBundle(context.Background(), nil, 100, newTx(t))
+	require.ErrorContains(t, err, "bundle already known")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.SendBundle(ctx, nil, 100, newTx(t))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	tx := newTx(t)
+	privResp, err := client.SendPrivateRawTransaction(context.Background(), tx)
+	require.NoError(t, err)
+	require.Equal(t, tx.Hash().Hex(), privResp.Result)
+	require.Len(t, relay.Requests(), 3)
+}
@@ -0,0 +1,67 @@
+package mev
+
+// SenderNamer is implemented by senders told apart from the other senders of their type,
+// e.g. the regions of a builder.
+type SenderNamer interface {
+	SenderName() string
+}
+
+// SenderName returns the name broadcast results are keyed by,
+// the name of a SenderNamer or else the sender type.
+func SenderName(s interface{ GetSenderType() BundleSenderType }) string {
+	if n, ok := s.(SenderNamer); ok {
+		return n.SenderName()
+	}
+
+	return s.GetSenderType().String()
+}
+
+// NamedSender names an IBundleSender, it must be the outermost wrapper for the name to be seen.
+type NamedSender struct {
+	IBundleSender
+	name string
+}
+
+var (
+	_ IBundleSender  = &NamedSender{}
+	_ HealthReporter = &NamedSender{}
+)
+
+func NewNamedSender(sender IBundleSender, name string) *NamedSender {
+	return &NamedSender{
+		IBundleSender: sender,
+		name:          name,
+	}
+}
+
+func (s *NamedSender) SenderName() string {
+	return s.name
+}
+
+// Health forwards the health of the wrapped sender, the circuit is closed if it does not report any.
+func (s *NamedSender) Health() BuilderHealth {
+	if r, ok := s.IBundleSender.(HealthReporter); ok {
+		return r.Health()
+	}
+
+	return BuilderHealth{SenderType: s.GetSenderType(), State: CircuitClosed}
+}
+
+// NamedBackrunSender is the IBackrunSender counterpart of NamedSender.
+type NamedBackrunSender struct {
+	IBackrunSender
+	name string
+}
+
+var _ IBackrunSender = &NamedBackrunSender{}
+
+func NewNamedBackrunSender(sender IBackrunSender, name string) *NamedBackrunSender {
+	return &NamedBackrunSender{
+		IBackrunSender: sender,
+		name:           name,
+	}
+}
+
+func (s *NamedBackrunSender) SenderName() string {
+	return s.name
+}
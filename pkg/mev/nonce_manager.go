@@ -0,0 +1,263 @@
+package mev
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceReader reads the nonce of an account, ChainReader and *ethclient.Client satisfy it.
+type NonceReader interface {
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// NonceReservation is a nonce handed out by a NonceManager, it is held until released, confirmed,
+// expired or consumed on chain.
+type NonceReservation struct {
+	Account common.Address
+	Nonce   uint64
+	// ExpiresAt is zero for a reservation that never expires.
+	ExpiresAt time.Time
+
+	m  *NonceManager
+	id uint64
+}
+
+// Release rolls the reservation back, the nonce is handed out again by the next Reserve.
+func (r *NonceReservation) Release() {
+	r.m.release(r)
+}
+
+// Confirm marks the nonce as consumed on chain without waiting for OnMinedBlock or Resync.
+func (r *NonceReservation) Confirm() {
+	r.m.advance(r.Account, r.Nonce+1)
+}
+
+type reservedNonce struct {
+	id        uint64
+	expiresAt time.Time
+}
+
+type accountNonces struct {
+	// chainNonce is the next nonce according to the chain.
+	chainNonce uint64
+	reserved   map[uint64]reservedNonce
+	// stale is set when the chain nonce is known to be wrong, the next Reserve resyncs it.
+	stale bool
+}
+
+// NonceManager hands out nonces per sender so txs signed concurrently for many builders do not collide.
+type NonceManager struct {
+	reader NonceReader
+
+	mu       sync.Mutex
+	accounts map[common.Address]*accountNonces
+	nextID   uint64
+}
+
+func NewNonceManager(reader NonceReader) *NonceManager {
+	return &NonceManager{
+		reader:   reader,
+		accounts: make(map[common.Address]*accountNonces),
+	}
+}
+
+// Reserve returns the lowest nonce of account which is neither used on chain nor reserved,
+// so nonces rolled back are reused first. A zero expiresAt never expires.
+func (m *NonceManager) Reserve(
+	ctx context.Context, account common.Address, expiresAt time.Time,
+) (*NonceReservation, error) {
+	if err := m.ensureSynced(ctx, account); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acc := m.accounts[account]
+	m.expire(acc)
+	nonce := acc.chainNonce
+	for {
+		if _, ok := acc.reserved[nonce]; !ok {
+			break
+		}
+		nonce++
+	}
+
+	m.nextID++
+	acc.reserved[nonce] = reservedNonce{id: m.nextID, expiresAt: expiresAt}
+
+	return &NonceReservation{
+		Account:   account,
+		Nonce:     nonce,
+		ExpiresAt: expiresAt,
+		m:         m,
+		id:        m.nextID,
+	}, nil
+}
+
+// Resync reads the nonce of account from the chain, reservations below it are dropped.
+// The chain nonce can go backwards on reorgs.
+func (m *NonceManager) Resync(ctx context.Context, account common.Address) error {
+	nonce, err := m.reader.NonceAt(ctx, account, nil)
+	if err != nil {
+		return fmt.Errorf("get nonce of %s: %w", account, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	acc := m.account(account)
+	acc.chainNonce = nonce
+	acc.stale = false
+	m.dropConsumed(acc)
+
+	return nil
+}
+
+// MarkStale makes the next Reserve of account read its nonce from the chain first,
+// it is used when the known chain nonce is wrong but a Resync failed.
+func (m *NonceManager) MarkStale(account common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.account(account).stale = true
+}
+
+// OnMinedBlock advances the chain nonce of every managed account sending a tx in block.
+func (m *NonceManager) OnMinedBlock(block tradingtypes.MinedBlock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tx := range block.Transactions {
+		acc, ok := m.accounts[tx.From]
+		if !ok || tx.Nonce < acc.chainNonce {
+			continue
+		}
+		acc.chainNonce = tx.Nonce + 1
+		m.dropConsumed(acc)
+	}
+}
+
+// ChainNonce returns the last known next nonce of account on chain.
+func (m *NonceManager) ChainNonce(account common.Address) (uint64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acc, ok := m.accounts[account]
+	if !ok {
+		return 0, false
+	}
+
+	return acc.chainNonce, true
+}
+
+// Gaps returns the free nonces below the highest reservation of account. A tx holding a nonce above
+// a gap can not be mined until the gap is filled.
+func (m *NonceManager) Gaps(account common.Address) []uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acc, ok := m.accounts[account]
+	if !ok {
+		return nil
+	}
+	m.expire(acc)
+
+	var highest uint64
+	for nonce := range acc.reserved {
+		highest = max(highest, nonce)
+	}
+
+	var gaps []uint64
+	for nonce := acc.chainNonce; nonce < highest; nonce++ {
+		if _, ok := acc.reserved[nonce]; !ok {
+			gaps = append(gaps, nonce)
+		}
+	}
+
+	return gaps
+}
+
+func (m *NonceManager) ensureSynced(ctx context.Context, account common.Address) error {
+	m.mu.Lock()
+	acc, ok := m.accounts[account]
+	synced := ok && !acc.stale
+	m.mu.Unlock()
+	if synced {
+		return nil
+	}
+
+	nonce, err := m.reader.NonceAt(ctx, account, nil)
+	if err != nil {
+		return fmt.Errorf("get nonce of %s: %w", account, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// another caller may have synced it meanwhile
+	if acc, ok := m.accounts[account]; !ok || acc.stale {
+		acc = m.account(account)
+		acc.chainNonce = nonce
+		acc.stale = false
+		m.dropConsumed(acc)
+	}
+
+	return nil
+}
+
+func (m *NonceManager) release(r *NonceReservation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acc, ok := m.accounts[r.Account]
+	if !ok {
+		return
+	}
+	// the nonce may have been handed out again after an expiry
+	if reserved, ok := acc.reserved[r.Nonce]; ok && reserved.id == r.id {
+		delete(acc.reserved, r.Nonce)
+	}
+}
+
+func (m *NonceManager) advance(account common.Address, next uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acc := m.account(account)
+	if next > acc.chainNonce {
+		acc.chainNonce = next
+		m.dropConsumed(acc)
+	}
+}
+
+// account must be called with mu held.
+func (m *NonceManager) account(account common.Address) *accountNonces {
+	acc, ok := m.accounts[account]
+	if !ok {
+		acc = &accountNonces{reserved: make(map[uint64]reservedNonce)}
+		m.accounts[account] = acc
+	}
+
+	return acc
+}
+
+func (m *NonceManager) dropConsumed(acc *accountNonces) {
+	for nonce := range acc.reserved {
+		if nonce < acc.chainNonce {
+			delete(acc.reserved, nonce)
+		}
+	}
+}
+
+func (m *NonceManager) expire(acc *accountNonces) {
+	now := time.Now()
+	for nonce, reserved := range acc.reserved {
+		if !reserved.expiresAt.IsZero() && now.After(reserved.expiresAt) {
+			delete(acc.reserved, nonce)
+		}
+	}
+}
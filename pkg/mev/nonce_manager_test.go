@@ -0,0 +1,187 @@
+package mev_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	tradingtypes "github.com/KyberNetwork/tradinglib/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonceManager(t *testing.T) {
+	account := common.HexToAddress("0x01")
+	chain := &fakeChain{nonces: map[common.Address]uint64{account: 5}}
+	m := mev.NewNonceManager(chain)
+	ctx := context.Background()
+
+	r5, err := m.Reserve(ctx, account, time.Time{})
+	require.NoError(t, err)
+	r6, err := m.Reserve(ctx, account, time.Now().Add(50*time.Millisecond))
+	require.NoError(t, err)
+	r7, err := m.Reserve(ctx, account, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{5, 6, 7}, []uint64{r5.Nonce, r6.Nonce, r7.Nonce})
+	time.Sleep(100 * time.Millisecond)
+
+	// the expired reservation leaves a gap which is filled first
+	require.Equal(t, []uint64{6}, m.Gaps(account))
+	r, err := m.Reserve(ctx, account, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), r.Nonce)
+	r6.Release() // stale, nonce 6 stays reserved
+	require.Empty(t, m.Gaps(account))
+
+	r5.Release()
+	require.Equal(t, []uint64{5}, m.Gaps(account))
+
+	// nonces mined in a block are dropped
+	m.OnMinedBlock(tradingtypes.MinedBlock{Transactions: []tradingtypes.Transaction{
+		{From: account, Nonce: 5},
+		{From: account, Nonce: 6},
+		{From: common.HexToAddress("0x02"), Nonce: 100},
+	}})
+	next, ok := m.ChainNonce(account)
+	require.True(t, ok)
+	require.Equal(t, uint64(7), next)
+	require.Empty(t, m.Gaps(account))
+	r, err = m.Reserve(ctx, account, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), r.Nonce)
+
+	// a reorg moves the chain nonce back
+	chain.nonces[account] = 6
+	require.NoError(t, m.Resync(ctx, account))
+	r, err = m.Reserve(ctx, account, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), r.Nonce)
+
+	r7.Confirm()
+	next, _ = m.ChainNonce(account)
+	require.Equal(t, uint64(8), next)
+}
+
+func TestBundleScheduler_ReleasesNonces(t *testing.T) {
+	tx := newSignedTx(t, 0)
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	require.NoError(t, err)
+	chain := &fakeChain{
+		head:     101,
+		receipts: map[common.Hash]*types.Receipt{},
+		nonces:   map[common.Address]uint64{},
+	}
+	scheduler, _ := newScheduler(t, chain)
+	m := mev.NewNonceManager(chain)
+
+	r, err := m.Reserve(context.Background(), from, time.Time{})
+	require.NoError(t, err)
+	result, err := scheduler.Schedule(context.Background(), mev.ScheduledBundle{
+		FromBlock: 100,
+		ToBlock:   101,
+		Txs:       []*types.Transaction{tx},
+		Nonces:    []*mev.NonceReservation{r},
+	})
+	require.NoError(t, err)
+	require.Equal(t, mev.BundleStatusExpired, result.Status)
+
+	// the nonce of the expired bundle is handed out again
+	r, err = m.Reserve(context.Background(), from, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), r.Nonce)
+}
+
+func TestBundleScheduler_FrontRunResyncsNonces(t *testing.T) {
+	tx := newSignedTx(t, 0)
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	require.NoError(t, err)
+	chain := &fakeChain{
+		head:     100,
+		receipts: map[common.Hash]*types.Receipt{},
+		nonces:   map[common.Address]uint64{},
+	}
+	scheduler, relay := newScheduler(t, chain)
+	m := mev.NewNonceManager(chain)
+
+	r, err := m.Reserve(context.Background(), from, time.Time{})
+	require.NoError(t, err)
+	go func() {
+		for len(relay.RequestsFor(mev.ETHSendBundleMethod)) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		chain.advance(func(c *fakeChain) { c.nonces[from] = 1 })
+	}()
+	result, err := scheduler.Schedule(context.Background(), mev.ScheduledBundle{
+		FromBlock: 100,
+		ToBlock:   101,
+		Txs:       []*types.Transaction{tx},
+		Nonces:    []*mev.NonceReservation{r},
+	})
+	require.NoError(t, err)
+	require.Equal(t, mev.BundleStatusFrontRun, result.Status)
+
+	// the nonce consumed by the other tx is not handed out again
+	r, err = m.Reserve(context.Background(), from, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), r.Nonce)
+}
+
+type flakyNonceReader struct {
+	mev.NonceReader
+	fail atomic.Bool
+}
+
+func (r *flakyNonceReader) NonceAt(ctx context.Context, account common.Address, block *big.Int) (uint64, error) {
+	if r.fail.Load() {
+		return 0, errors.New("node unavailable")
+	}
+
+	return r.NonceReader.NonceAt(ctx, account, block)
+}
+
+func TestBundleScheduler_FrontRunResyncFailureMarksStale(t *testing.T) {
+	tx := newSignedTx(t, 0)
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	require.NoError(t, err)
+	chain := &fakeChain{
+		head:     100,
+		receipts: map[common.Hash]*types.Receipt{},
+		nonces:   map[common.Address]uint64{},
+	}
+	scheduler, relay := newScheduler(t, chain)
+	reader := &flakyNonceReader{NonceReader: chain}
+	m := mev.NewNonceManager(reader)
+
+	r0, err := m.Reserve(context.Background(), from, time.Time{})
+	require.NoError(t, err)
+	r1, err := m.Reserve(context.Background(), from, time.Time{})
+	require.NoError(t, err)
+	go func() {
+		for len(relay.RequestsFor(mev.ETHSendBundleMethod)) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		reader.fail.Store(true)
+		chain.advance(func(c *fakeChain) { c.nonces[from] = 2 })
+	}()
+	result, err := scheduler.Schedule(context.Background(), mev.ScheduledBundle{
+		FromBlock: 100,
+		ToBlock:   101,
+		Txs:       []*types.Transaction{tx},
+		Nonces:    []*mev.NonceReservation{r0, r1},
+	})
+	require.NoError(t, err)
+	require.Equal(t, mev.BundleStatusFrontRun, result.Status)
+
+	// no nonce is handed out until the chain nonce is known again
+	_, err = m.Reserve(context.Background(), from, time.Time{})
+	require.Error(t, err)
+	reader.fail.Store(false)
+	r, err := m.Reserve(context.Background(), from, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), r.Nonce)
+}
@@ -60,11 +60,15 @@ const (
 	ETHCancelBundleMethod        = "eth_cancelBundle"
 	ETHEstimateGasBundleMethod   = "eth_estimateGasBundle"
 	ETHSendPrivateRawTransaction = "eth_sendPrivateRawTransaction"
+	ETHSendPrivateTransaction    = "eth_sendPrivateTransaction"
 	MevSendBundleMethod          = "mev_sendBundle"
+	MevSimBundleMethod           = "mev_simBundle"
 	FlashbotGetUserStats         = "flashbots_getUserStats"
 	FlashbotGetUserStatsV2       = "flashbots_getUserStatsV2"
 	TitanGetUserStats            = "titan_getUserStats"
 	ETHSendRawTransaction        = "eth_sendRawTransaction"
+	// ETHSendRawTransactionConditional is supported by the Optimism, Base and Arbitrum sequencers.
+	ETHSendRawTransactionConditional = "eth_sendRawTransactionConditional"
 
 	MaxBlockFromTarget = 3
 )
@@ -153,8 +157,9 @@ type IGasBundleEstimator interface {
 }
 
 var (
-	_ IBundleSender = &Client{}
-	_ IBundleSender = &BloxrouteClient{}
+	_ IBundleSender  = &Client{}
+	_ IBundleSender  = &BloxrouteClient{}
+	_ IBackrunSender = &Client{}
 )
 
 var defaultHeaders = [][2]string{ // nolint: gochecknoglobals
@@ -180,6 +185,7 @@ var defaultHeaders = [][2]string{ // nolint: gochecknoglobals
 // 	return rlp
 // }
 
+// doRequest returns a *BuilderError on non OK status, and on JSON-RPC error for responses implementing rpcErrorer.
 func doRequest[T any](c *http.Client, req *http.Request, headers ...[2]string) (T, error) {
 	var t T
 
@@ -191,7 +197,7 @@ func doRequest[T any](c *http.Client, req *http.Request, headers ...[2]string) (
 	}
 	httpResp, err := c.Do(req)
 	if err != nil {
-		return t, fmt.Errorf("do request error: %w", err)
+		return t, transportError(err)
 	}
 	defer httpResp.Body.Close()
 
@@ -201,14 +207,17 @@ func doRequest[T any](c *http.Client, req *http.Request, headers ...[2]string) (
 	}
 
 	if httpResp.StatusCode != http.StatusOK {
-		return t, fmt.Errorf("not OK status, status: [%d], data: [%s]",
-			httpResp.StatusCode, string(respBody))
+		return t, newBuilderError(httpResp.StatusCode, 0, "", respBody)
 	}
 
 	if err := json.Unmarshal(respBody, &t); err != nil {
 		return t, fmt.Errorf("unmarshal response error: %w, data: [%s]", err, string(respBody))
 	}
 
+	if r, ok := any(t).(rpcErrorer); ok && len(r.rpcError().Messange) != 0 {
+		return t, newBuilderError(httpResp.StatusCode, r.rpcError().Code, r.rpcError().Messange, respBody)
+	}
+
 	return t, nil
 }
 
@@ -219,7 +228,9 @@ type GetBundleStatsResult struct {
 	ReceivedAt     time.Time `json:"receivedAt,omitempty"`
 
 	ConsideredByBuildersAt []*struct {
-		Pubkey    string    `json:"pubkey,omitempty"`
+		Pubkey string `json:"pubkey,omitempty"`
+		// Builder is set instead of Pubkey by relays naming builders, e.g. the bloXroute bundle trace.
+		Builder   string    `json:"builder,omitempty"`
 		Timestamp time.Time `json:"timestamp"`
 	} `json:"consideredByBuildersAt,omitempty"`
 	SealedByBuildersAt []*struct {
@@ -275,6 +286,7 @@ type SendBundleResult struct {
 	Results           []SendBundleResults `json:"results,omitempty"`
 	StateBlockNumber  int                 `json:"stateBlockNumber,omitempty"`
 	TotalGasUsed      int                 `json:"totalGasUsed,omitempty"`
+	TotalBlobGasUsed  int                 `json:"totalBlobGasUsed,omitempty"`
 	Message           string              `json:"message,omitempty"`
 }
 
@@ -307,9 +319,19 @@ func (r *SendBundleResult) UnmarshalJSON(b []byte) error {
 }
 
 type SendBundleResults struct {
-	GasUsed int    `json:"gasUsed,omitempty"`
-	TxHash  string `json:"txHash,omitempty"`
-	Value   string `json:"value,omitempty"`
+	GasUsed           int          `json:"gasUsed,omitempty"`
+	TxHash            string       `json:"txHash,omitempty"`
+	Value             string       `json:"value,omitempty"`
+	FromAddress       string       `json:"fromAddress,omitempty"`
+	ToAddress         string       `json:"toAddress,omitempty"`
+	GasPrice          string       `json:"gasPrice,omitempty"`
+	GasFees           string       `json:"gasFees,omitempty"`
+	CoinbaseDiff      string       `json:"coinbaseDiff,omitempty"`
+	EthSentToCoinbase string       `json:"ethSentToCoinbase,omitempty"`
+	Error             string       `json:"error,omitempty"`
+	Revert            string       `json:"revert,omitempty"`
+	Logs              []*types.Log `json:"logs,omitempty"`
+	BlobGasUsed       int          `json:"blobGasUsed,omitempty"`
 }
 
 type FlashbotCancelBundleResponse struct {
@@ -356,6 +378,18 @@ func ToCallArg(msg ethereum.CallMsg) interface{} {
 	if msg.GasPrice != nil {
 		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
 	}
+	if len(msg.AccessList) > 0 {
+		arg["accessList"] = msg.AccessList
+	}
+	if len(msg.BlobHashes) > 0 {
+		arg["blobVersionedHashes"] = msg.BlobHashes
+	}
+	if msg.BlobGasFeeCap != nil {
+		arg["maxFeePerBlobGas"] = (*hexutil.Big)(msg.BlobGasFeeCap)
+	}
+	if len(msg.AuthorizationList) > 0 {
+		arg["authorizationList"] = msg.AuthorizationList
+	}
 	return arg
 }
 
@@ -383,4 +417,18 @@ type SendBundleV2Request struct {
 	ReplacementUUID *string `json:"ReplacementUuid,omitempty"`
 	// (Optional) String, UUID that can be used to cancel/replace this bundle (For beaverbuild)
 	UUID *string `json:"uuid,omitempty"`
+
+	// The options below are not supported by every builder, SendBundleV2 returns a *BundleOptionError
+	// unwrapping to ErrBundleOptionNotSupported for an option the builder does not accept.
+
+	// (Optional) Number, the percent of the bundle profit refunded to RefundRecipient
+	RefundPercent *int `json:"refundPercent,omitempty"`
+	// (Optional) Address, receives the refund, defaults to the sender of the first refunded tx
+	RefundRecipient *common.Address `json:"refundRecipient,omitempty"`
+	// (Optional) Array[String], the tx hashes whose profit is refunded
+	RefundTxHashes *[]string `json:"refundTxHashes,omitempty"`
+	// (Optional) Array[String], A list of tx hashes that are dropped from the bundle instead of failing it
+	DroppingTxs *[]string `json:"droppingTxHashes,omitempty"`
+	// (Optional) Array[String], A list of builders the bundle is shared with
+	Builders *[]string `json:"builders,omitempty"`
 }
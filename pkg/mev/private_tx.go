@@ -0,0 +1,295 @@
+package mev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/flashbots/mev-share-node/mevshare"
+)
+
+const defaultPrivateTxPollInterval = 2 * time.Second
+
+// PrivateTxPreferences are the eth_sendPrivateTransaction preferences of Flashbots Protect,
+// RPCs that do not support a preference ignore it.
+// https://docs.flashbots.net/flashbots-protect/additional-documentation/eth-sendPrivateTransaction
+type PrivateTxPreferences struct {
+	// Fast shares the tx with every registered builder.
+	Fast bool
+	// Builders restricts the builders the tx is shared with, see FlashbotBuilderRegistration*.
+	Builders []string
+	// Hints selects the data shared with MEV-Share searchers, e.g. mevshare.HintCallData|mevshare.HintLogs.
+	Hints mevshare.HintIntent
+	// MaxBlockNumber is the last block the tx is valid for, 0 leaves the RPC default (25 blocks).
+	MaxBlockNumber uint64
+	Refund         []mevshare.RefundConfig
+}
+
+type SendPrivateTransactionParams struct {
+	Tx             string                       `json:"tx"`
+	MaxBlockNumber string                       `json:"maxBlockNumber,omitempty"`
+	Preferences    *privateTransactionPrefsJSON `json:"preferences,omitempty"`
+}
+
+type privateTransactionPrefsJSON struct {
+	Fast     bool                            `json:"fast"`
+	Privacy  *privateTransactionPrivacyJSON  `json:"privacy,omitempty"`
+	Validity *privateTransactionValidityJSON `json:"validity,omitempty"`
+}
+
+type privateTransactionPrivacyJSON struct {
+	Hints    mevshare.HintIntent `json:"hints,omitempty"`
+	Builders []string            `json:"builders,omitempty"`
+}
+
+type privateTransactionValidityJSON struct {
+	Refund []mevshare.RefundConfig `json:"refund,omitempty"`
+}
+
+func newSendPrivateTransactionParams(
+	tx *types.Transaction, prefs PrivateTxPreferences,
+) (SendPrivateTransactionParams, error) {
+	txBin, err := tx.MarshalBinary()
+	if err != nil {
+		return SendPrivateTransactionParams{}, fmt.Errorf("marshal tx binary: %w", err)
+	}
+
+	p := SendPrivateTransactionParams{
+		Tx: hexutil.Encode(txBin),
+		Preferences: &privateTransactionPrefsJSON{
+			Fast: prefs.Fast,
+		},
+	}
+	if prefs.MaxBlockNumber != 0 {
+		p.MaxBlockNumber = hexutil.EncodeUint64(prefs.MaxBlockNumber)
+	}
+	if prefs.Hints != mevshare.HintNone || len(prefs.Builders) != 0 {
+		p.Preferences.Privacy = &privateTransactionPrivacyJSON{
+			Hints:    prefs.Hints,
+			Builders: prefs.Builders,
+		}
+	}
+	if len(prefs.Refund) != 0 {
+		p.Preferences.Validity = &privateTransactionValidityJSON{Refund: prefs.Refund}
+	}
+
+	return p, nil
+}
+
+// IPrivateTxSender is implemented by private RPCs supporting eth_sendPrivateTransaction,
+// e.g. Flashbots Protect, MEV Blocker and BuilderNet.
+type IPrivateTxSender interface {
+	SendPrivateTransaction(
+		ctx context.Context,
+		tx *types.Transaction,
+		prefs PrivateTxPreferences,
+	) (SendPrivateRawTransactionResponse, error)
+	GetSenderType() BundleSenderType
+}
+
+var _ IPrivateTxSender = &Client{}
+
+// SendPrivateTransaction sends tx with eth_sendPrivateTransaction, the result is the tx hash.
+// Unlike SendPrivateRawTransaction it does not need WithSendPrivateRaw.
+func (s *Client) SendPrivateTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+	prefs PrivateTxPreferences,
+) (SendPrivateRawTransactionResponse, error) {
+	p, err := newSendPrivateTransactionParams(tx, prefs)
+	if err != nil {
+		return SendPrivateRawTransactionResponse{}, err
+	}
+	httpReq, headers, err := s.newSignedRequest(ctx, ETHSendPrivateTransaction, p)
+	if err != nil {
+		return SendPrivateRawTransactionResponse{}, err
+	}
+
+	return doRequest[SendPrivateRawTransactionResponse](s.c, httpReq, headers...)
+}
+
+type PrivateTxStatus string
+
+const (
+	PrivateTxStatusPending  PrivateTxStatus = "pending"
+	PrivateTxStatusIncluded PrivateTxStatus = "included"
+	PrivateTxStatusFailed   PrivateTxStatus = "failed"
+	// PrivateTxStatusDropped means the RPC stopped trying to include the tx, e.g. it expired or was cancelled.
+	PrivateTxStatusDropped PrivateTxStatus = "dropped"
+)
+
+type PrivateTxStatusResult struct {
+	TxHash common.Hash
+	Status PrivateTxStatus
+	// BlockNumber is set when the status provider knows it.
+	BlockNumber uint64
+}
+
+// PrivateTxStatusProvider reports the inclusion status of a private tx.
+type PrivateTxStatusProvider interface {
+	PrivateTxStatus(ctx context.Context, txHash common.Hash) (PrivateTxStatusResult, error)
+}
+
+// ProtectStatusClient reads the Flashbots Protect status API.
+// https://docs.flashbots.net/flashbots-protect/additional-documentation/status-api
+type ProtectStatusClient struct {
+	c        *http.Client
+	endpoint string
+}
+
+func NewProtectStatusClient(c *http.Client, endpoint string) *ProtectStatusClient {
+	if endpoint == "" {
+		endpoint = EndpointFlashbotProtectStatus
+	}
+
+	return &ProtectStatusClient{
+		c:        c,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+	}
+}
+
+type ProtectTxStatusResponse struct {
+	Status         string `json:"status"`
+	Hash           string `json:"hash"`
+	MaxBlockNumber uint64 `json:"maxBlockNumber"`
+	SeenInMempool  bool   `json:"seenInMempool"`
+}
+
+func (p *ProtectStatusClient) PrivateTxStatus(
+	ctx context.Context, txHash common.Hash,
+) (PrivateTxStatusResult, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+"/tx/"+txHash.Hex(), nil)
+	if err != nil {
+		return PrivateTxStatusResult{}, fmt.Errorf("new http request error: %w", err)
+	}
+	resp, err := doRequest[ProtectTxStatusResponse](p.c, httpReq)
+	if err != nil {
+		return PrivateTxStatusResult{}, err
+	}
+
+	result := PrivateTxStatusResult{TxHash: txHash}
+	switch resp.Status {
+	case "INCLUDED":
+		result.Status = PrivateTxStatusIncluded
+	case "FAILED":
+		result.Status = PrivateTxStatusFailed
+	case "CANCELLED":
+		result.Status = PrivateTxStatusDropped
+	default:
+		// PENDING, and UNKNOWN until Protect has seen the tx
+		result.Status = PrivateTxStatusPending
+	}
+
+	return result, nil
+}
+
+// ReceiptStatusProvider derives the status from the tx receipt, for RPCs without a status API.
+// It never reports a dropped tx, the polling context must bound the wait.
+type ReceiptStatusProvider struct {
+	reader ChainReader
+}
+
+func NewReceiptStatusProvider(reader ChainReader) *ReceiptStatusProvider {
+	return &ReceiptStatusProvider{reader: reader}
+}
+
+func (p *ReceiptStatusProvider) PrivateTxStatus(
+	ctx context.Context, txHash common.Hash,
+) (PrivateTxStatusResult, error) {
+	result := PrivateTxStatusResult{TxHash: txHash, Status: PrivateTxStatusPending}
+	receipt, err := p.reader.TransactionReceipt(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return PrivateTxStatusResult{}, fmt.Errorf("get receipt of tx %s: %w", txHash, err)
+	}
+
+	result.Status = PrivateTxStatusIncluded
+	if receipt.Status == types.ReceiptStatusFailed {
+		result.Status = PrivateTxStatusFailed
+	}
+	if receipt.BlockNumber != nil {
+		result.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+
+	return result, nil
+}
+
+// PrivateTxResult is the outcome of one private RPC.
+type PrivateTxResult = SenderResult[SendPrivateRawTransactionResponse]
+
+type PrivateTxResults map[string]PrivateTxResult
+
+// Err joins the errors of all failed RPCs, it returns nil if every RPC accepted the tx.
+func (r PrivateTxResults) Err() error {
+	return joinSenderErrors(r)
+}
+
+// PrivateTxRouter fans out private transactions to several private RPCs and follows their inclusion.
+type PrivateTxRouter struct {
+	senders      []IPrivateTxSender
+	status       PrivateTxStatusProvider
+	pollInterval time.Duration
+}
+
+// NewPrivateTxRouter polls status every pollInterval, a zero interval defaults to 2 seconds.
+// It returns an error if two senders share the same SenderName, because results are keyed by it.
+func NewPrivateTxRouter(
+	senders []IPrivateTxSender, status PrivateTxStatusProvider, pollInterval time.Duration,
+) (*PrivateTxRouter, error) {
+	if pollInterval <= 0 {
+		pollInterval = defaultPrivateTxPollInterval
+	}
+
+	if err := checkSenderNames(senders); err != nil {
+		return nil, err
+	}
+
+	return &PrivateTxRouter{
+		senders:      senders,
+		status:       status,
+		pollInterval: pollInterval,
+	}, nil
+}
+
+// Send submits tx with prefs to every RPC concurrently and waits for all of them.
+// RPCs implementing HealthReporter with an open circuit are skipped and reported with ErrCircuitOpen.
+func (r *PrivateTxRouter) Send(
+	ctx context.Context, tx *types.Transaction, prefs PrivateTxPreferences,
+) PrivateTxResults {
+	return broadcast(ctx, broadcasterOptions{}, r.senders,
+		func(ctx context.Context, s IPrivateTxSender) (SendPrivateRawTransactionResponse, error) {
+			return s.SendPrivateTransaction(ctx, tx, prefs)
+		})
+}
+
+// Wait polls the status of txHash until it is included, failed or dropped.
+// Status errors are retried on the next poll, the context bounds the wait.
+func (r *PrivateTxRouter) Wait(ctx context.Context, txHash common.Hash) (PrivateTxStatusResult, error) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		result, err := r.status.PrivateTxStatus(ctx, txHash)
+		if err == nil && result.Status != PrivateTxStatusPending {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return PrivateTxStatusResult{}, errors.Join(ctx.Err(), err)
+			}
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
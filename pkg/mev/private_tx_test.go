@@ -0,0 +1,122 @@
+package mev_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/flashbots/mev-share-node/mevshare"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateTxRouter(t *testing.T) {
+	protect := mevtest.NewRelay(mev.BundleSenderTypeFlashbot)
+	defer protect.Close()
+	mevBlocker := mevtest.NewRelay(mev.BundleSenderTypeMevBlocker)
+	defer mevBlocker.Close()
+	mevBlocker.Script(mev.ETHSendPrivateTransaction, mevtest.Response{HTTPStatus: http.StatusBadGateway})
+
+	var polls atomic.Int32
+	statusAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := "PENDING"
+		if polls.Add(1) > 2 {
+			status = "INCLUDED"
+		}
+		_, _ = fmt.Fprintf(w, `{"status":%q,"hash":%q,"maxBlockNumber":20,"seenInMempool":false}`,
+			status, r.URL.Path[len("/tx/"):])
+	}))
+	defer statusAPI.Close()
+
+	newClient := func(relay *mevtest.Relay, senderType mev.BundleSenderType) mev.IPrivateTxSender {
+		client, err := mev.NewClient(relay.Client(), relay.URL(), nil, senderType, false)
+		require.NoError(t, err)
+		return client
+	}
+	statusClient := mev.NewProtectStatusClient(statusAPI.Client(), statusAPI.URL+"/")
+	router, err := mev.NewPrivateTxRouter([]mev.IPrivateTxSender{
+		newClient(protect, mev.BundleSenderTypeFlashbot),
+		newClient(mevBlocker, mev.BundleSenderTypeMevBlocker),
+	}, statusClient, time.Millisecond)
+	require.NoError(t, err)
+
+	_, err = mev.NewPrivateTxRouter([]mev.IPrivateTxSender{
+		newClient(protect, mev.BundleSenderTypeFlashbot),
+		newClient(mevBlocker, mev.BundleSenderTypeFlashbot),
+	}, statusClient, time.Millisecond)
+	require.ErrorIs(t, err, mev.ErrDuplicateSenderName)
+
+	tx := newSignedTx(t, 0)
+	refund := common.HexToAddress("0x01")
+	results := router.Send(context.Background(), tx, mev.PrivateTxPreferences{
+		Fast:           true,
+		Builders:       []string{mev.FlashbotBuilderRegistrationTitan},
+		Hints:          mevshare.HintCallData | mevshare.HintHash,
+		MaxBlockNumber: 20,
+		Refund:         []mevshare.RefundConfig{{Address: refund, Percent: 90}},
+	})
+	require.Len(t, results, 2)
+	require.NoError(t, results[mev.BundleSenderTypeFlashbot.String()].Err)
+	require.Equal(t, tx.Hash().Hex(), results[mev.BundleSenderTypeFlashbot.String()].Response.Result)
+	require.ErrorIs(t, results.Err(), mev.ErrBuilderUnavailable)
+	require.ErrorContains(t, results.Err(), mev.BundleSenderTypeMevBlocker.String()+": ")
+
+	requests := protect.RequestsFor(mev.ETHSendPrivateTransaction)
+	require.Len(t, requests, 1)
+	require.JSONEq(t, fmt.Sprintf(`[{
+		"tx": %q,
+		"maxBlockNumber": "0x14",
+		"preferences": {
+			"fast": true,
+			"privacy": {"hints": ["calldata", "hash"], "builders": ["Titan"]},
+			"validity": {"refund": [{"address": %q, "percent": 90}]}
+		}
+	}]`, rawTx(t, tx), refund.Hex()), string(requests[0].Params))
+
+	status, err := router.Wait(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, mev.PrivateTxStatusIncluded, status.Status)
+	require.Equal(t, tx.Hash(), status.TxHash)
+	require.EqualValues(t, 3, polls.Load())
+}
+
+func TestReceiptStatusProvider(t *testing.T) {
+	chain := &fakeChain{receipts: map[common.Hash]*types.Receipt{}}
+	provider := mev.NewReceiptStatusProvider(chain)
+	txHash := common.HexToHash("0x01")
+
+	status, err := provider.PrivateTxStatus(context.Background(), txHash)
+	require.NoError(t, err)
+	require.Equal(t, mev.PrivateTxStatusPending, status.Status)
+
+	chain.receipts[txHash] = &types.Receipt{Status: types.ReceiptStatusFailed, BlockNumber: big.NewInt(7)}
+	status, err = provider.PrivateTxStatus(context.Background(), txHash)
+	require.NoError(t, err)
+	require.Equal(t, mev.PrivateTxStatusFailed, status.Status)
+	require.Equal(t, uint64(7), status.BlockNumber)
+
+	// pending until the context ends
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	router, err := mev.NewPrivateTxRouter(nil, provider, time.Millisecond)
+	require.NoError(t, err)
+	_, err = router.Wait(ctx, common.HexToHash("0x02"))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func rawTx(t *testing.T, tx *types.Transaction) string {
+	t.Helper()
+	b, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	return hexutil.Encode(b)
+}
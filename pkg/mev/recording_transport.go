@@ -0,0 +1,388 @@
+package mev
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	redactedHeaderValue  = "[REDACTED]"
+	maxRecordingLineSize = 32 << 20
+)
+
+// nolint: gochecknoglobals
+var defaultRedactedHeaders = []string{XFlashbotSignatureHeader, "Authorization"}
+
+// defaultRedactedBodyFields holds the request body keys carrying credentials,
+// mev_builders of blxr_submit_bundle maps each builder to an "address:signature" pair.
+// nolint: gochecknoglobals
+var defaultRedactedBodyFields = []string{"mev_builders"}
+
+// Recording is one builder request/response pair, stored as a line of a JSONL archive.
+type Recording struct {
+	Time           time.Time   `json:"time"`
+	HTTPMethod     string      `json:"httpMethod"`
+	URL            string      `json:"url"`
+	RPCMethod      string      `json:"rpcMethod,omitempty"`
+	RequestHeader  http.Header `json:"requestHeader,omitempty"`
+	RequestBody    string      `json:"requestBody,omitempty"`
+	StatusCode     int         `json:"statusCode,omitempty"`
+	ResponseHeader http.Header `json:"responseHeader,omitempty"`
+	ResponseBody   string      `json:"responseBody,omitempty"`
+	Error          string      `json:"error,omitempty"`
+}
+
+type RecordingOption func(recordingOptions) recordingOptions
+
+type recordingOptions struct {
+	redactedHeaders    []string
+	redactedBodyFields []string
+}
+
+// WithRedactedHeaders redacts the given request headers in addition to X-Flashbots-Signature and Authorization.
+func WithRedactedHeaders(headers ...string) RecordingOption {
+	return func(opt recordingOptions) recordingOptions {
+		opt.redactedHeaders = append(opt.redactedHeaders, headers...)
+		return opt
+	}
+}
+
+// WithRedactedBodyFields redacts the values of the given JSON object keys wherever they appear in a request body,
+// in addition to mev_builders. The keys of an object value are kept, only its leaf values are redacted.
+func WithRedactedBodyFields(keys ...string) RecordingOption {
+	return func(opt recordingOptions) recordingOptions {
+		opt.redactedBodyFields = append(opt.redactedBodyFields, keys...)
+		return opt
+	}
+}
+
+func newRecordingOptions(opts []RecordingOption) recordingOptions {
+	options := recordingOptions{
+		redactedHeaders:    append([]string(nil), defaultRedactedHeaders...),
+		redactedBodyFields: append([]string(nil), defaultRedactedBodyFields...),
+	}
+	for i := range opts {
+		if opts[i] == nil {
+			continue
+		}
+		options = opts[i](options)
+	}
+
+	return options
+}
+
+// RecordingTransport is an http.RoundTripper writing every request/response pair to a JSONL archive,
+// it is meant to be set as the Transport of the *http.Client given to NewClient and friends.
+type RecordingTransport struct {
+	inner http.RoundTripper
+	opts  recordingOptions
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+var _ http.RoundTripper = &RecordingTransport{}
+
+// NewRecordingTransport records the traffic of inner into w, http.DefaultTransport is used when inner is nil.
+func NewRecordingTransport(inner http.RoundTripper, w io.Writer, opts ...RecordingOption) *RecordingTransport {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	return &RecordingTransport{
+		inner: inner,
+		opts:  newRecordingOptions(opts),
+		enc:   json.NewEncoder(w),
+	}
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, reqBody, err := cloneWithBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("read request body error: %w", err)
+	}
+
+	rec := Recording{
+		Time:          time.Now(),
+		HTTPMethod:    req.Method,
+		URL:           req.URL.String(),
+		RPCMethod:     rpcMethodOf(reqBody),
+		RequestHeader: t.redact(req.Header),
+		RequestBody:   string(redactBody(reqBody, t.opts.redactedBodyFields)),
+	}
+
+	resp, err := t.inner.RoundTrip(req)
+	if err != nil {
+		rec.Error = err.Error()
+		t.write(rec)
+		return nil, err
+	}
+
+	respBody, err := drainBody(&resp.Body)
+	if err != nil {
+		rec.Error = err.Error()
+		t.write(rec)
+		return nil, fmt.Errorf("read response body error: %w", err)
+	}
+	rec.StatusCode = resp.StatusCode
+	rec.ResponseHeader = resp.Header.Clone()
+	rec.ResponseBody = string(respBody)
+	t.write(rec)
+
+	return resp, nil
+}
+
+func (t *RecordingTransport) redact(h http.Header) http.Header {
+	h = h.Clone()
+	for _, key := range t.opts.redactedHeaders {
+		if h.Get(key) != "" {
+			h.Set(key, redactedHeaderValue)
+		}
+	}
+
+	return h
+}
+
+// redactBody returns body with the values of keys redacted, body is returned as is when it is not JSON
+// or holds none of keys.
+func redactBody(body []byte, keys []string) []byte {
+	if len(keys) == 0 {
+		return body
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	if !redactFields(v, keys) {
+		return body
+	}
+	redacted, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+
+	return redacted
+}
+
+func redactFields(v any, keys []string) bool {
+	var changed bool
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if slices.Contains(keys, k) {
+				v[k] = redactValue(field)
+				changed = true
+				continue
+			}
+			changed = redactFields(field, keys) || changed
+		}
+	case []any:
+		for _, item := range v {
+			changed = redactFields(item, keys) || changed
+		}
+	}
+
+	return changed
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			v[k] = redactValue(field)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	default:
+		return redactedHeaderValue
+	}
+}
+
+// write never fails the request, losing a recording is better than losing a bundle.
+func (t *RecordingTransport) write(rec Recording) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_ = t.enc.Encode(rec)
+}
+
+// ReadRecordings reads a JSONL archive written by a RecordingTransport.
+func ReadRecordings(r io.Reader) ([]Recording, error) {
+	var recs []Recording
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxRecordingLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var rec Recording
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("unmarshal recording at line %d error: %w", line, err)
+		}
+		recs = append(recs, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read recordings error: %w", err)
+	}
+
+	return recs, nil
+}
+
+// ReplayTransport is an http.RoundTripper serving recordings back.
+// Recordings are served in order per URL and JSON-RPC method, a request whose body is not the recorded one
+// (compared as JSON) fails with ErrReplayMismatch so serialisation changes are caught.
+// Request bodies are redacted as by the RecordingTransport before being compared, so the same RecordingOption
+// must be given to both.
+type ReplayTransport struct {
+	opts recordingOptions
+
+	mu      sync.Mutex
+	pending map[replayKey][]Recording
+}
+
+type replayKey struct {
+	url       string
+	rpcMethod string
+}
+
+var _ http.RoundTripper = &ReplayTransport{}
+
+func NewReplayTransport(recs []Recording, opts ...RecordingOption) *ReplayTransport {
+	pending := make(map[replayKey][]Recording)
+	for _, rec := range recs {
+		key := replayKey{url: rec.URL, rpcMethod: rec.RPCMethod}
+		pending[key] = append(pending[key], rec)
+	}
+
+	return &ReplayTransport{opts: newRecordingOptions(opts), pending: pending}
+}
+
+// Remaining returns the number of recordings not served yet.
+func (t *ReplayTransport) Remaining() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var n int
+	for _, recs := range t.pending {
+		n += len(recs)
+	}
+
+	return n
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, reqBody, err := cloneWithBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("read request body error: %w", err)
+	}
+
+	key := replayKey{url: req.URL.String(), rpcMethod: rpcMethodOf(reqBody)}
+	t.mu.Lock()
+	recs := t.pending[key]
+	if len(recs) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s %s", ErrReplayExhausted, key.url, key.rpcMethod)
+	}
+	rec := recs[0]
+	t.pending[key] = recs[1:]
+	t.mu.Unlock()
+
+	if !sameBody(redactBody(reqBody, t.opts.redactedBodyFields), []byte(rec.RequestBody)) {
+		return nil, fmt.Errorf("%w: %s %s, recorded: [%s], got: [%s]",
+			ErrReplayMismatch, key.url, key.rpcMethod, rec.RequestBody, string(reqBody))
+	}
+	if rec.Error != "" {
+		return nil, fmt.Errorf("replayed error: %s", rec.Error)
+	}
+
+	header := rec.ResponseHeader.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(rec.ResponseBody))),
+		ContentLength: int64(len(rec.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// cloneWithBody reads the body of req and returns a clone of req reading an in-memory copy of it,
+// a RoundTripper must not modify the request it is given.
+func cloneWithBody(req *http.Request) (*http.Request, []byte, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	req = req.Clone(req.Context())
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	return req, body, nil
+}
+
+// drainBody reads body and replaces it by an in-memory copy.
+func drainBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	*body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func rpcMethodOf(body []byte) string {
+	var req struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+
+	return req.Method
+}
+
+func sameBody(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	ca, errA := json.Marshal(va)
+	cb, errB := json.Marshal(vb)
+
+	return errA == nil && errB == nil && bytes.Equal(ca, cb)
+}
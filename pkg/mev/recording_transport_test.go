@@ -0,0 +1,122 @@
+package mev_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingTransport_RecordAndReplay(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeFlashbot)
+	defer relay.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx := newSignedTx(t, 0)
+
+	var archive bytes.Buffer
+	recording := &http.Client{Transport: mev.NewRecordingTransport(relay.Client().Transport, &archive)}
+	client, err := mev.NewClient(recording, relay.URL(), key, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	recorded, err := client.SendBundle(context.Background(), nil, 100, tx)
+	require.NoError(t, err)
+
+	require.NotContains(t, archive.String(), relay.Requests()[0].Header.Get(mev.XFlashbotSignatureHeader))
+	recs, err := mev.ReadRecordings(strings.NewReader(archive.String()))
+	require.NoError(t, err)
+	require.Len(t, recs, 1)
+	require.Equal(t, mev.ETHSendBundleMethod, recs[0].RPCMethod)
+	require.Equal(t, "[REDACTED]", recs[0].RequestHeader.Get(mev.XFlashbotSignatureHeader))
+
+	replay := mev.NewReplayTransport(recs)
+	client, err = mev.NewClient(&http.Client{Transport: replay}, relay.URL(), key, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	replayed, err := client.SendBundle(context.Background(), nil, 100, tx)
+	require.NoError(t, err)
+	require.Equal(t, recorded, replayed)
+	require.Zero(t, replay.Remaining())
+	require.Len(t, relay.Requests(), 1)
+
+	_, err = client.SendBundle(context.Background(), nil, 100, tx)
+	require.ErrorIs(t, err, mev.ErrReplayExhausted)
+
+	// a different payload is reported as a mismatch
+	replay = mev.NewReplayTransport(recs)
+	client, err = mev.NewClient(&http.Client{Transport: replay}, relay.URL(), key, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	_, err = client.SendBundle(context.Background(), nil, 101, tx)
+	require.ErrorIs(t, err, mev.ErrReplayMismatch)
+}
+
+func TestRecordingTransport_DoesNotModifyRequest(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeFlashbot)
+	defer relay.Close()
+
+	var archive bytes.Buffer
+	transport := mev.NewRecordingTransport(relay.Client().Transport, &archive)
+	body := io.NopCloser(strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"eth_sendBundle","params":[]}`))
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, relay.URL(), body)
+	require.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.True(t, req.Body == body)
+	require.NotSame(t, req, resp.Request)
+
+	recs, err := mev.ReadRecordings(strings.NewReader(archive.String()))
+	require.NoError(t, err)
+	replay := mev.NewReplayTransport(recs)
+	body = io.NopCloser(strings.NewReader(recs[0].RequestBody))
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, relay.URL(), body)
+	require.NoError(t, err)
+	_, err = replay.RoundTrip(req)
+	require.NoError(t, err)
+	require.True(t, req.Body == body)
+}
+
+func TestRecordingTransport_RedactsBloxrouteBuilderSignatures(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeBloxroute, mevtest.WithAuthorization("secret"))
+	defer relay.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx := newSignedTx(t, 0)
+
+	var archive bytes.Buffer
+	recording := &http.Client{Transport: mev.NewRecordingTransport(relay.Client().Transport, &archive)}
+	_, err = mev.NewBloxrouteClient(recording, relay.URL(), "secret", key, mev.BuilderFlashbot).
+		SendBundle(context.Background(), nil, 100, tx)
+	require.NoError(t, err)
+
+	var sent struct {
+		Params struct {
+			MEVBuilders map[string]string `json:"mev_builders"`
+		} `json:"params"`
+	}
+	require.NoError(t, json.Unmarshal(relay.Requests()[0].Body, &sent))
+	signature := sent.Params.MEVBuilders["flashbots"]
+	require.NotEmpty(t, signature)
+	require.NotContains(t, archive.String(), signature)
+	require.NotContains(t, archive.String(), "secret")
+
+	recs, err := mev.ReadRecordings(strings.NewReader(archive.String()))
+	require.NoError(t, err)
+	require.Len(t, recs, 1)
+	require.Contains(t, recs[0].RequestBody, `"mev_builders":{"flashbots":"[REDACTED]"}`)
+
+	// the live body is redacted the same way before being compared
+	replay := mev.NewReplayTransport(recs)
+	_, err = mev.NewBloxrouteClient(&http.Client{Transport: replay}, relay.URL(), "secret", key, mev.BuilderFlashbot).
+		SendBundle(context.Background(), nil, 100, tx)
+	require.NoError(t, err)
+	require.Zero(t, replay.Remaining())
+}
@@ -0,0 +1,422 @@
+package mev
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"gopkg.in/yaml.v3"
+)
+
+type CancelStrategy string
+
+const (
+	// CancelStrategyCancelBundle cancels with eth_cancelBundle.
+	CancelStrategyCancelBundle CancelStrategy = "cancel_bundle"
+	// CancelStrategySendBundle cancels by sending an empty bundle with the same replacement uuid.
+	CancelStrategySendBundle CancelStrategy = "send_bundle"
+)
+
+type Capability string
+
+const (
+	CapabilityBundle    Capability = "bundle"
+	CapabilityBackrun   Capability = "backrun"
+	CapabilityPrivateTx Capability = "private_tx"
+	CapabilityRawTx     Capability = "raw_tx"
+)
+
+const (
+	RegionUS   = "us"
+	RegionEU   = "eu"
+	RegionAsia = "asia"
+)
+
+// BuilderConfig declares one builder endpoint.
+// Endpoint and AuthHeader are expanded with environment variables, so secrets can be kept out of the document.
+type BuilderConfig struct {
+	// ID is the builder ID (e.g. BuilderTitanID), it defaults to the ID of the sender type.
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+	// Name identifies the entry in broadcast results, it defaults to the ID followed by the region if any.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Type is a BundleSenderType name, with or without the "BundleSenderType" prefix (e.g. "Titan").
+	Type string `json:"type" yaml:"type"`
+	// Endpoint defaults to the known endpoint of the sender type and region.
+	Endpoint   string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Region     string `json:"region,omitempty" yaml:"region,omitempty"`
+	AuthHeader string `json:"auth_header,omitempty" yaml:"auth_header,omitempty"`
+	// SigningKey is a reference resolved by the KeyResolver, empty means unsigned requests.
+	SigningKey string `json:"signing_key,omitempty" yaml:"signing_key,omitempty"`
+	// SignatureHeader is used by backrun senders, it defaults to XFlashbotSignatureHeader.
+	SignatureHeader   string         `json:"signature_header,omitempty" yaml:"signature_header,omitempty"`
+	CancelStrategy    CancelStrategy `json:"cancel_strategy,omitempty" yaml:"cancel_strategy,omitempty"`
+	RefundAddress     string         `json:"refund_address,omitempty" yaml:"refund_address,omitempty"`
+	Capabilities      []Capability   `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+	BloxrouteBuilders []BlxrBuilder  `json:"bloxroute_builders,omitempty" yaml:"bloxroute_builders,omitempty"`
+	Disabled          bool           `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+func (c BuilderConfig) hasCapability(capability Capability) bool {
+	return slices.Contains(c.Capabilities, capability)
+}
+
+// supportedCapabilities returns the capabilities a sender type can be declared with.
+func supportedCapabilities(senderType BundleSenderType) []Capability {
+	switch senderType {
+	case BundleSenderTypeBloxroute:
+		return []Capability{CapabilityBundle}
+	case BundleSenderTypeBloxrouteBackrunme, BundleSenderTypeMevShare,
+		BundleSenderTypeMerkle, BundleSenderTypeBackrunPublic:
+		return []Capability{CapabilityBackrun}
+	case BundleSenderTypeL2:
+		return []Capability{CapabilityRawTx}
+	default:
+		return []Capability{CapabilityBundle, CapabilityBackrun, CapabilityPrivateTx}
+	}
+}
+
+func validateCapabilities(senderType BundleSenderType, capabilities []Capability) error {
+	if len(capabilities) == 0 {
+		return fmt.Errorf("%w: no capability declared", ErrInvalidCapability)
+	}
+	supported := supportedCapabilities(senderType)
+	for _, capability := range capabilities {
+		switch capability {
+		case CapabilityBundle, CapabilityBackrun, CapabilityPrivateTx, CapabilityRawTx:
+		default:
+			return fmt.Errorf("%w: unknown capability %q", ErrInvalidCapability, capability)
+		}
+		if !slices.Contains(supported, capability) {
+			return fmt.Errorf("%w: %s does not support %s", ErrInvalidCapability, senderType, capability)
+		}
+	}
+
+	return nil
+}
+
+type RegistryConfig struct {
+	Builders []BuilderConfig `json:"builders" yaml:"builders"`
+}
+
+// ParseRegistryConfig parses a YAML or JSON registry document.
+func ParseRegistryConfig(data []byte) (RegistryConfig, error) {
+	var cfg RegistryConfig
+	// JSON is valid YAML
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return RegistryConfig{}, fmt.Errorf("unmarshal registry config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// KeyResolver resolves a signing key reference of a BuilderConfig.
+type KeyResolver func(ref string) (*ecdsa.PrivateKey, error)
+
+// EnvKeyResolver treats the reference as the name of an environment variable holding a hex private key.
+func EnvKeyResolver(ref string) (*ecdsa.PrivateKey, error) {
+	hexKey, ok := os.LookupEnv(ref)
+	if !ok {
+		return nil, fmt.Errorf("%w: env %s is not set", ErrMissingPrivKey, ref)
+	}
+
+	return crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+}
+
+// RegistryEntry is a configured builder with the senders built for its capabilities,
+// a sender is nil when the builder does not declare its capability.
+type RegistryEntry struct {
+	Config          BuilderConfig
+	SenderType      BundleSenderType
+	BundleSender    IBundleSender
+	BackrunSender   IBackrunSender
+	PrivateTxSender IPrivateTxSender
+	RawTxSender     ISendRawTransaction
+}
+
+type Registry struct {
+	entries []RegistryEntry
+}
+
+// NewRegistry builds the senders of every enabled builder in cfg.
+func NewRegistry(c *http.Client, cfg RegistryConfig, keys KeyResolver) (*Registry, error) {
+	r := &Registry{
+		entries: make([]RegistryEntry, 0, len(cfg.Builders)),
+	}
+	names := make(map[string]struct{}, len(cfg.Builders))
+	for i, bc := range cfg.Builders {
+		if bc.Disabled {
+			continue
+		}
+		entry, err := newRegistryEntry(c, bc, keys)
+		if err != nil {
+			return nil, fmt.Errorf("builder %d (%s): %w", i, bc.Type, err)
+		}
+		if _, ok := names[entry.Config.Name]; ok {
+			return nil, fmt.Errorf("builder %d (%s): %w: %s", i, bc.Type, ErrDuplicateSenderName, entry.Config.Name)
+		}
+		names[entry.Config.Name] = struct{}{}
+		r.entries = append(r.entries, entry)
+	}
+
+	return r, nil
+}
+
+func (r *Registry) Entries() []RegistryEntry {
+	return slices.Clone(r.entries)
+}
+
+func (r *Registry) ByType(senderType BundleSenderType) []RegistryEntry {
+	var out []RegistryEntry
+	for _, e := range r.entries {
+		if e.SenderType == senderType {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// ByID returns the entries of a builder ID, e.g. BuilderTitanID returns every Titan region.
+func (r *Registry) ByID(id string) []RegistryEntry {
+	var out []RegistryEntry
+	for _, e := range r.entries {
+		if e.Config.ID == id {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// BundleSenders returns the bundle senders named after their entry, restricted to the given regions if any.
+// Builders without region are always returned.
+func (r *Registry) BundleSenders(regions ...string) []IBundleSender {
+	var out []IBundleSender
+	for _, e := range r.entries {
+		if e.BundleSender != nil && inRegions(e.Config.Region, regions) {
+			out = append(out, NewNamedSender(e.BundleSender, e.Config.Name))
+		}
+	}
+
+	return out
+}
+
+// BackrunSenders returns the backrun senders named after their entry, restricted to the given regions if any.
+func (r *Registry) BackrunSenders(regions ...string) []IBackrunSender {
+	var out []IBackrunSender
+	for _, e := range r.entries {
+		if e.BackrunSender != nil && inRegions(e.Config.Region, regions) {
+			out = append(out, NewNamedBackrunSender(e.BackrunSender, e.Config.Name))
+		}
+	}
+
+	return out
+}
+
+// NewBroadcaster returns a Broadcaster over the bundle and backrun senders of the given regions,
+// its results are keyed by the entry names.
+func (r *Registry) NewBroadcaster(regions []string, opts ...BroadcasterOption) (*Broadcaster, error) {
+	return NewBroadcaster(r.BundleSenders(regions...), r.BackrunSenders(regions...), opts...)
+}
+
+func inRegions(region string, regions []string) bool {
+	return len(regions) == 0 || region == "" || slices.Contains(regions, region)
+}
+
+func parseSenderType(s string) (BundleSenderType, error) {
+	if t, err := BundleSenderTypeString(s); err == nil {
+		return t, nil
+	}
+
+	return BundleSenderTypeString("BundleSenderType" + s)
+}
+
+// nolint: cyclop
+func newRegistryEntry(c *http.Client, bc BuilderConfig, keys KeyResolver) (RegistryEntry, error) {
+	senderType, err := parseSenderType(bc.Type)
+	if err != nil {
+		return RegistryEntry{}, err
+	}
+
+	bc.Endpoint = os.ExpandEnv(bc.Endpoint)
+	bc.AuthHeader = os.ExpandEnv(bc.AuthHeader)
+	if bc.Endpoint == "" {
+		bc.Endpoint = BuilderEndpoint(senderType, bc.Region)
+	}
+	if bc.ID == "" {
+		bc.ID = BuilderID(senderType)
+	}
+	if bc.Name == "" {
+		bc.Name = bc.ID
+		if bc.Name == "" {
+			bc.Name = senderType.String()
+		}
+		if bc.Region != "" {
+			bc.Name += "-" + bc.Region
+		}
+	}
+	if bc.SignatureHeader == "" {
+		bc.SignatureHeader = XFlashbotSignatureHeader
+	}
+	if bc.Endpoint == "" && senderType != BundleSenderTypeBloxrouteBackrunme {
+		return RegistryEntry{}, fmt.Errorf("missing endpoint")
+	}
+	if err := validateCapabilities(senderType, bc.Capabilities); err != nil {
+		return RegistryEntry{}, err
+	}
+
+	var key *ecdsa.PrivateKey
+	if bc.SigningKey != "" {
+		if keys == nil {
+			return RegistryEntry{}, fmt.Errorf("%w: no key resolver for %s", ErrMissingPrivKey, bc.SigningKey)
+		}
+		if key, err = keys(bc.SigningKey); err != nil {
+			return RegistryEntry{}, fmt.Errorf("resolve signing key %s: %w", bc.SigningKey, err)
+		}
+	}
+
+	entry := RegistryEntry{
+		Config:     bc,
+		SenderType: senderType,
+	}
+	switch senderType {
+	case BundleSenderTypeBloxroute:
+		entry.BundleSender = NewBloxrouteClient(c, bc.Endpoint, bc.AuthHeader, key, bc.BloxrouteBuilders...)
+	case BundleSenderTypeBloxrouteBackrunme:
+		entry.BackrunSender, err = NewBloxrouteBackrunmeSender(bc.AuthHeader, bc.Endpoint)
+	case BundleSenderTypeMevShare:
+		entry.BackrunSender, err = NewMevShareSender(bc.Endpoint, key)
+	case BundleSenderTypeMerkle:
+		entry.BackrunSender = NewMerkleClient(c, bc.Endpoint, key, senderType, bc.SignatureHeader)
+	case BundleSenderTypeBackrunPublic:
+		entry.BackrunSender = NewBackrunPublicClient(c, bc.Endpoint, key, senderType, bc.SignatureHeader)
+	case BundleSenderTypeL2:
+		entry.RawTxSender = NewL2ChainSender(c, bc.Endpoint, senderType)
+	default:
+		entry, err = newClientEntry(c, bc, key, entry)
+	}
+	if err != nil {
+		return RegistryEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// newClientEntry builds the senders of a Client based builder, each one only if its capability is declared.
+func newClientEntry(c *http.Client, bc BuilderConfig, key *ecdsa.PrivateKey, entry RegistryEntry) (RegistryEntry, error) {
+	if bc.hasCapability(CapabilityBundle) || bc.hasCapability(CapabilityPrivateTx) {
+		var opts []NewBundleSendleClientOption
+		if bc.hasCapability(CapabilityPrivateTx) {
+			opts = append(opts, WithSendPrivateRaw())
+		}
+		if bc.RefundAddress != "" {
+			opts = append(opts, WithBuilderNetRefundAddress(bc.RefundAddress))
+		}
+		client, err := NewClient(c, bc.Endpoint, key, entry.SenderType,
+			bc.CancelStrategy == CancelStrategySendBundle, opts...)
+		if err != nil {
+			return RegistryEntry{}, err
+		}
+		if bc.hasCapability(CapabilityBundle) {
+			entry.BundleSender = client
+		}
+		if bc.hasCapability(CapabilityPrivateTx) {
+			entry.PrivateTxSender = client
+		}
+	}
+	if bc.hasCapability(CapabilityBackrun) {
+		entry.BackrunSender = NewBackrunPublicClient(c, bc.Endpoint, key, entry.SenderType, bc.SignatureHeader)
+	}
+
+	return entry, nil
+}
+
+// BuilderID returns the builder ID of a sender type, empty if the sender type is not a single builder.
+func BuilderID(senderType BundleSenderType) string {
+	switch senderType {
+	case BundleSenderTypeFlashbot:
+		return BuilderFlashbotID
+	case BundleSenderTypeBeaver:
+		return BuilderBeaverbuildID
+	case BundleSenderTypeRsync:
+		return BuilderRsyncID
+	case BundleSenderTypeTitan:
+		return BuilderTitanID
+	case BundleSenderTypeBloxroute, BundleSenderTypeBloxrouteBackrunme:
+		return BuilderBloxrouteID
+	case BundleSenderTypeMevBlocker:
+		return BuilderMevBlockerID
+	case BundleSenderTypeBlink:
+		return BuilderBlinkID
+	case BundleSenderTypeMerkle:
+		return BuilderMerkleID
+	case BundleSenderTypeJetbldr:
+		return BuilderJetbldrID
+	case BundleSenderTypePenguin:
+		return BuilderPenguinID
+	case BundleSenderTypeLoki:
+		return BuilderLokiID
+	case BundleSenderTypeQuasar:
+		return BuilderQuasarID
+	case BundleSenderTypeBuilderNet:
+		return BuilderNetID
+	case BundleSenderTypeBTCS:
+		return BuilderBTCS
+	case BundleSenderTypeEureka:
+		return BuilderEureka
+	case BundleSenderBobTheBuilder:
+		return BuilderBobTheBuilder
+	default:
+		return ""
+	}
+}
+
+// BuilderEndpoint returns the known public endpoint of a sender type, region only matters for Titan.
+// nolint: cyclop
+func BuilderEndpoint(senderType BundleSenderType, region string) string {
+	switch senderType {
+	case BundleSenderTypeFlashbot, BundleSenderTypeMevShare:
+		return EndpointFlashbot
+	case BundleSenderTypeBeaver:
+		return EndpointBeaverbuild
+	case BundleSenderTypeRsync:
+		return EndpointRsync
+	case BundleSenderTypeTitan:
+		switch region {
+		case RegionEU:
+			return EndpointTitanEU
+		case RegionAsia:
+			return EndpointTitanAsia
+		default:
+			return EndpointTitanUS
+		}
+	case BundleSenderTypeBloxroute:
+		return EndpointBloxroute
+	case BundleSenderTypeBloxrouteBackrunme:
+		return DefaultEndpoint
+	case BundleSenderTypeMevBlocker:
+		return EndpointMevBlocker
+	case BundleSenderTypeMerkle:
+		return EndpointMerkle
+	case BundleSenderTypeJetbldr:
+		return EndpointJetbldr
+	case BundleSenderTypeLoki:
+		return EndpointLoki
+	case BundleSenderTypeQuasar:
+		return EndpointQuasar
+	case BundleSenderTypeBuilderNet:
+		return EndpointBuilderNet
+	case BundleSenderTypeBTCS:
+		return EndpointBTCS
+	case BundleSenderTypeEureka:
+		return EndpointEureka
+	case BundleSenderBobTheBuilder:
+		return EndpointBobTheBuilder
+	default:
+		return ""
+	}
+}
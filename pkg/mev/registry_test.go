@@ -0,0 +1,168 @@
+package mev_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeFlashbot)
+	defer relay.Close()
+	t.Setenv("FLASHBOT_RELAY", relay.URL())
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	keys := func(ref string) (*ecdsa.PrivateKey, error) {
+		require.Equal(t, "flashbot", ref)
+		return key, nil
+	}
+
+	cfg, err := mev.ParseRegistryConfig([]byte(`
+builders:
+  - type: Titan
+    region: us
+    capabilities: [bundle]
+  - type: BundleSenderTypeTitan
+    region: eu
+    cancel_strategy: send_bundle
+    capabilities: [bundle]
+  - type: Bloxroute
+    auth_header: secret
+    bloxroute_builders: [bloxroute, titan]
+    capabilities: [bundle]
+  - type: Merkle
+    region: eu
+    capabilities: [backrun]
+  - type: Beaver
+    disabled: true
+  - type: Flashbot
+    endpoint: ${FLASHBOT_RELAY}
+    signing_key: flashbot
+    refund_address: "0x0000000000000000000000000000000000000001"
+    capabilities: [bundle, private_tx]
+`))
+	require.NoError(t, err)
+
+	registry, err := mev.NewRegistry(relay.Client(), cfg, keys)
+	require.NoError(t, err)
+	require.Len(t, registry.Entries(), 5)
+	require.Empty(t, registry.ByType(mev.BundleSenderTypeBeaver))
+
+	titan := registry.ByID(mev.BuilderTitanID)
+	require.Len(t, titan, 2)
+	require.Equal(t, mev.EndpointTitanUS, titan[0].Config.Endpoint)
+	require.Equal(t, mev.EndpointTitanEU, titan[1].Config.Endpoint)
+	require.Equal(t, mev.BuilderTitanID+"-us", titan[0].Config.Name)
+	require.Equal(t, mev.BuilderTitanID+"-eu", titan[1].Config.Name)
+
+	require.Len(t, registry.BundleSenders(), 4)
+	require.Len(t, registry.BundleSenders(mev.RegionUS), 3)
+	require.Len(t, registry.BackrunSenders(mev.RegionUS), 0)
+	require.Len(t, registry.BackrunSenders(mev.RegionEU), 1)
+
+	flashbot := registry.ByType(mev.BundleSenderTypeFlashbot)
+	require.Len(t, flashbot, 1)
+	require.Equal(t, mev.BuilderFlashbotID, flashbot[0].Config.ID)
+	require.NotNil(t, flashbot[0].PrivateTxSender)
+	require.Nil(t, flashbot[0].BackrunSender)
+
+	_, err = flashbot[0].BundleSender.SendBundle(context.Background(), nil, 1, newSignedTx(t, 0))
+	require.NoError(t, err)
+	requests := relay.RequestsFor(mev.ETHSendBundleMethod)
+	require.Len(t, requests, 1)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), requests[0].Signer)
+
+	var params []map[string]any
+	require.NoError(t, json.Unmarshal(requests[0].Params, &params))
+	require.Equal(t, "0x0000000000000000000000000000000000000001", params[0]["builderNetRefundAddress"])
+
+	_, err = flashbot[0].BundleSender.SendPrivateRawTransaction(context.Background(), newSignedTx(t, 1))
+	require.NoError(t, err)
+}
+
+func TestRegistry_InvalidConfig(t *testing.T) {
+	_, err := mev.NewRegistry(nil, mev.RegistryConfig{
+		Builders: []mev.BuilderConfig{{Type: "Unknown"}},
+	}, nil)
+	require.Error(t, err)
+
+	_, err = mev.NewRegistry(nil, mev.RegistryConfig{
+		Builders: []mev.BuilderConfig{{
+			Type:         "Flashbot",
+			SigningKey:   "missing",
+			Capabilities: []mev.Capability{mev.CapabilityBundle},
+		}},
+	}, mev.EnvKeyResolver)
+	require.ErrorIs(t, err, mev.ErrMissingPrivKey)
+
+	for _, capabilities := range [][]mev.Capability{
+		nil,
+		{"unknown"},
+		{mev.CapabilityRawTx},
+	} {
+		_, err = mev.NewRegistry(nil, mev.RegistryConfig{
+			Builders: []mev.BuilderConfig{{Type: "Titan", Capabilities: capabilities}},
+		}, nil)
+		require.ErrorIs(t, err, mev.ErrInvalidCapability)
+	}
+}
+
+func TestRegistry_RawTxOnly(t *testing.T) {
+	registry, err := mev.NewRegistry(nil, mev.RegistryConfig{
+		Builders: []mev.BuilderConfig{{
+			Type:         "L2",
+			Endpoint:     "http://localhost:8545",
+			Capabilities: []mev.Capability{mev.CapabilityRawTx},
+		}},
+	}, nil)
+	require.NoError(t, err)
+	entries := registry.Entries()
+	require.Len(t, entries, 1)
+	require.NotNil(t, entries[0].RawTxSender)
+	require.Nil(t, entries[0].BundleSender)
+	require.Nil(t, entries[0].BackrunSender)
+	require.Nil(t, entries[0].PrivateTxSender)
+	require.Empty(t, registry.BundleSenders())
+}
+
+func TestRegistry_BroadcastRegions(t *testing.T) {
+	us := mevtest.NewRelay(mev.BundleSenderTypeTitan)
+	defer us.Close()
+	eu := mevtest.NewRelay(mev.BundleSenderTypeTitan)
+	defer eu.Close()
+
+	registry, err := mev.NewRegistry(us.Client(), mev.RegistryConfig{Builders: []mev.BuilderConfig{
+		{Type: "Titan", Region: mev.RegionUS, Endpoint: us.URL(), Capabilities: []mev.Capability{mev.CapabilityBundle}},
+		{Type: "Titan", Region: mev.RegionEU, Endpoint: eu.URL(), Capabilities: []mev.Capability{mev.CapabilityBundle}},
+	}}, nil)
+	require.NoError(t, err)
+
+	b, err := registry.NewBroadcaster(nil)
+	require.NoError(t, err)
+	results := b.SendBundleV2(context.Background(), mev.SendBundleV2Request{}, newSignedTx(t, 0))
+	require.NoError(t, results.Err())
+	require.Len(t, results, 2)
+	require.Contains(t, results, mev.BuilderTitanID+"-us")
+	require.Contains(t, results, mev.BuilderTitanID+"-eu")
+	require.Len(t, us.RequestsFor(mev.ETHSendBundleMethod), 1)
+	require.Len(t, eu.RequestsFor(mev.ETHSendBundleMethod), 1)
+
+	b, err = registry.NewBroadcaster([]string{mev.RegionEU})
+	require.NoError(t, err)
+	results = b.SendBundleV2(context.Background(), mev.SendBundleV2Request{}, newSignedTx(t, 0))
+	require.Len(t, results, 1)
+	require.Contains(t, results, mev.BuilderTitanID+"-eu")
+
+	_, err = mev.NewRegistry(nil, mev.RegistryConfig{Builders: []mev.BuilderConfig{
+		{Type: "Titan", Capabilities: []mev.Capability{mev.CapabilityBundle}},
+		{Type: "Titan", Capabilities: []mev.Capability{mev.CapabilityBundle}},
+	}}, nil)
+	require.ErrorIs(t, err, mev.ErrDuplicateSenderName)
+}
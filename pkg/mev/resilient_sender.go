@@ -0,0 +1,379 @@
+package mev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/rate"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	defaultMaxRetries       = 2
+	defaultBaseBackoff      = 50 * time.Millisecond
+	defaultMaxBackoff       = time.Second
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// BuilderHealth is a snapshot of the circuit breaker of one builder.
+type BuilderHealth struct {
+	SenderType          BundleSenderType
+	State               CircuitState
+	ConsecutiveFailures int
+	OpenedAt            time.Time
+	LastLatency         time.Duration
+	LastError           error
+}
+
+// HealthReporter is implemented by senders exposing their builder health, the Broadcaster skips open circuits.
+type HealthReporter interface {
+	Health() BuilderHealth
+}
+
+type ResilienceOption func(resilienceOptions) resilienceOptions
+
+type resilienceOptions struct {
+	maxRetries        int
+	baseBackoff       time.Duration
+	maxBackoff        time.Duration
+	failureThreshold  int
+	slowCallThreshold time.Duration
+	openTimeout       time.Duration
+	limiter           *rate.Limiter
+}
+
+// WithMaxRetries sets the number of retries after the first attempt, 0 disables retrying.
+func WithMaxRetries(n int) ResilienceOption {
+	return func(opt resilienceOptions) resilienceOptions {
+		opt.maxRetries = n
+		return opt
+	}
+}
+
+// WithBackoff sets the exponential backoff between retries, starting at base and capped at maxBackoff.
+func WithBackoff(base, maxBackoff time.Duration) ResilienceOption {
+	return func(opt resilienceOptions) resilienceOptions {
+		opt.baseBackoff = base
+		opt.maxBackoff = maxBackoff
+		return opt
+	}
+}
+
+// WithCircuitBreaker opens the circuit after failureThreshold consecutive failures and probes the builder
+// again after openTimeout. Calls slower than slowCallThreshold count as failures, 0 disables it.
+func WithCircuitBreaker(failureThreshold int, slowCallThreshold, openTimeout time.Duration) ResilienceOption {
+	return func(opt resilienceOptions) resilienceOptions {
+		opt.failureThreshold = failureThreshold
+		opt.slowCallThreshold = slowCallThreshold
+		opt.openTimeout = openTimeout
+		return opt
+	}
+}
+
+// WithRateLimiter throttles every request to the builder.
+func WithRateLimiter(l *rate.Limiter) ResilienceOption {
+	return func(opt resilienceOptions) resilienceOptions {
+		opt.limiter = l
+		return opt
+	}
+}
+
+// ResilientSender decorates an IBundleSender with retries, a circuit breaker and client-side throttling.
+// SendBundle, SendBundleV2, SendBundleHex and SimulateBundle are retried,
+// CancelBundle and SendPrivateRawTransaction go through the breaker and the limiter only.
+// A retry rejected as already known fails with ErrBundleAlreadySubmitted, the bundle hash of the
+// earlier attempt is not known.
+type ResilientSender struct {
+	IBundleSender
+	opts    resilienceOptions
+	breaker *circuitBreaker
+}
+
+var (
+	_ IBundleSender  = &ResilientSender{}
+	_ HealthReporter = &ResilientSender{}
+)
+
+func NewResilientSender(sender IBundleSender, opts ...ResilienceOption) *ResilientSender {
+	options := resilienceOptions{
+		maxRetries:       defaultMaxRetries,
+		baseBackoff:      defaultBaseBackoff,
+		maxBackoff:       defaultMaxBackoff,
+		failureThreshold: defaultFailureThreshold,
+		openTimeout:      defaultOpenTimeout,
+	}
+	for i := range opts {
+		if opts[i] == nil {
+			continue
+		}
+		options = opts[i](options)
+	}
+
+	return &ResilientSender{
+		IBundleSender: sender,
+		opts:          options,
+		breaker: &circuitBreaker{
+			senderType:       sender.GetSenderType(),
+			failureThreshold: options.failureThreshold,
+			openTimeout:      options.openTimeout,
+		},
+	}
+}
+
+func (s *ResilientSender) Health() BuilderHealth {
+	return s.breaker.health()
+}
+
+func (s *ResilientSender) SendBundle(
+	ctx context.Context,
+	uuid *string,
+	blockNumber uint64,
+	txs ...*types.Transaction,
+) (SendBundleResponse, error) {
+	return s.retry(ctx, func(ctx context.Context) (SendBundleResponse, error) {
+		return s.IBundleSender.SendBundle(ctx, uuid, blockNumber, txs...)
+	})
+}
+
+func (s *ResilientSender) SendBundleV2(
+	ctx context.Context,
+	req SendBundleV2Request,
+	txs ...*types.Transaction,
+) (SendBundleResponse, error) {
+	return s.retry(ctx, func(ctx context.Context) (SendBundleResponse, error) {
+		return s.IBundleSender.SendBundleV2(ctx, req, txs...)
+	})
+}
+
+func (s *ResilientSender) SendBundleHex(
+	ctx context.Context,
+	uuid *string,
+	blockNumber uint64,
+	hexEncodedTxs ...string,
+) (SendBundleResponse, error) {
+	return s.retry(ctx, func(ctx context.Context) (SendBundleResponse, error) {
+		return s.IBundleSender.SendBundleHex(ctx, uuid, blockNumber, hexEncodedTxs...)
+	})
+}
+
+func (s *ResilientSender) SimulateBundle(
+	ctx context.Context, blockNumber uint64, txs ...*types.Transaction,
+) (SendBundleResponse, error) {
+	return s.retry(ctx, func(ctx context.Context) (SendBundleResponse, error) {
+		return s.IBundleSender.SimulateBundle(ctx, blockNumber, txs...)
+	})
+}
+
+func (s *ResilientSender) CancelBundle(ctx context.Context, bundleUUID string) error {
+	if err := s.acquire(ctx); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := s.IBundleSender.CancelBundle(ctx, bundleUUID)
+	s.breaker.record(err, time.Since(start), s.opts.slowCallThreshold)
+
+	return err
+}
+
+func (s *ResilientSender) SendPrivateRawTransaction(
+	ctx context.Context,
+	tx *types.Transaction,
+) (SendPrivateRawTransactionResponse, error) {
+	if err := s.acquire(ctx); err != nil {
+		return SendPrivateRawTransactionResponse{}, err
+	}
+	start := time.Now()
+	resp, err := s.IBundleSender.SendPrivateRawTransaction(ctx, tx)
+	s.breaker.record(err, time.Since(start), s.opts.slowCallThreshold)
+
+	return resp, err
+}
+
+func (s *ResilientSender) acquire(ctx context.Context) error {
+	if err := s.breaker.allow(); err != nil {
+		return err
+	}
+	if s.opts.limiter != nil {
+		if err := s.opts.limiter.Wait(ctx); err != nil {
+			s.breaker.release()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ResilientSender) retry(
+	ctx context.Context, call func(context.Context) (SendBundleResponse, error),
+) (SendBundleResponse, error) {
+	for attempt := 0; ; attempt++ {
+		if err := s.acquire(ctx); err != nil {
+			return SendBundleResponse{}, err
+		}
+
+		start := time.Now()
+		resp, err := call(ctx)
+		s.breaker.record(err, time.Since(start), s.opts.slowCallThreshold)
+		if err == nil {
+			return resp, nil
+		}
+		// a previous attempt reached the builder even though we did not get its response nor its bundle hash
+		if attempt > 0 && errors.Is(err, ErrBundleAlreadyKnown) {
+			return resp, fmt.Errorf("%w: %w", ErrBundleAlreadySubmitted, err)
+		}
+		if attempt >= s.opts.maxRetries || !isRetryable(err) {
+			return resp, err
+		}
+
+		wait := s.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return resp, err
+		}
+		select {
+		case <-ctx.Done():
+			return resp, err
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (s *ResilientSender) backoff(attempt int) time.Duration {
+	wait := s.opts.baseBackoff << attempt
+	if wait <= 0 || wait > s.opts.maxBackoff {
+		return s.opts.maxBackoff
+	}
+
+	return wait
+}
+
+func isRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrBuilderUnavailable)
+}
+
+// isBuilderFailure reports whether err is the builder's fault, rejected bundles do not count.
+func isBuilderFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if isRetryable(err) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var builderErr *BuilderError
+	return errors.As(err, &builderErr) && builderErr.HTTPStatus >= http.StatusInternalServerError
+}
+
+type circuitBreaker struct {
+	senderType       BundleSenderType
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mu                  sync.Mutex
+	state               CircuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool
+	lastLatency         time.Duration
+	lastError           error
+}
+
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return nil
+	case CircuitHalfOpen:
+		// only one probe at a time
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// release gives back a probe slot that was not used.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) record(err error, latency, slowCallThreshold time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	// a cancelled call tells nothing about the builder
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	b.lastLatency = latency
+	b.lastError = err
+	failed := isBuilderFailure(err) || (slowCallThreshold > 0 && latency > slowCallThreshold)
+	if !failed {
+		b.state = CircuitClosed
+		b.consecutiveFailures = 0
+		return
+	}
+
+	b.consecutiveFailures++
+	if b.state == CircuitHalfOpen || (b.failureThreshold > 0 && b.consecutiveFailures >= b.failureThreshold) {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *circuitBreaker) health() BuilderHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == CircuitOpen && time.Since(b.openedAt) >= b.openTimeout {
+		state = CircuitHalfOpen
+	}
+
+	return BuilderHealth{
+		SenderType:          b.senderType,
+		State:               state,
+		ConsecutiveFailures: b.consecutiveFailures,
+		OpenedAt:            b.openedAt,
+		LastLatency:         b.lastLatency,
+		LastError:           b.lastError,
+	}
+}
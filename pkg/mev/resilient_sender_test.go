@@ -0,0 +1,108 @@
+package mev_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/KyberNetwork/tradinglib/pkg/rate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResilientSender_Retry(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeTitan)
+	defer relay.Close()
+	relay.Script(mev.ETHSendBundleMethod,
+		mevtest.Response{HTTPStatus: http.StatusServiceUnavailable},
+		mevtest.Response{HTTPStatus: http.StatusTooManyRequests},
+	)
+
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeTitan, false)
+	require.NoError(t, err)
+	sender := mev.NewResilientSender(client, mev.WithBackoff(time.Millisecond, 5*time.Millisecond))
+
+	resp, err := sender.SendBundle(context.Background(), nil, 1, newSignedTx(t, 0))
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Result.BundleHash)
+	require.Len(t, relay.RequestsFor(mev.ETHSendBundleMethod), 3)
+	require.Equal(t, mev.CircuitClosed, sender.Health().State)
+
+	// the first attempt reached the builder but its response was lost
+	relay.Script(mev.ETHSendBundleMethod,
+		mevtest.Response{HTTPStatus: http.StatusServiceUnavailable},
+		mevtest.Response{Error: &mev.ErrorResponse{Code: -32000, Messange: "bundle already known"}},
+	)
+	resp, err = sender.SendBundle(context.Background(), nil, 1, newSignedTx(t, 0))
+	require.ErrorIs(t, err, mev.ErrBundleAlreadySubmitted)
+	require.ErrorIs(t, err, mev.ErrBundleAlreadyKnown)
+	require.Empty(t, resp.Result.BundleHash)
+
+	// rejected bundles are not retried
+	relay.Script(mev.ETHSendBundleMethod, mevtest.Response{
+		Error: &mev.ErrorResponse{Code: -32000, Messange: "nonce too low"},
+	})
+	_, err = sender.SendBundle(context.Background(), nil, 1, newSignedTx(t, 0))
+	require.ErrorIs(t, err, mev.ErrNonceTooLow)
+	require.Len(t, relay.RequestsFor(mev.ETHSendBundleMethod), 6)
+}
+
+func TestResilientSender_CircuitBreaker(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeBeaver)
+	defer relay.Close()
+	for range 3 {
+		relay.Script(mev.EthCallBundleMethod, mevtest.Response{HTTPStatus: http.StatusBadGateway})
+	}
+
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeBeaver, false)
+	require.NoError(t, err)
+	sender := mev.NewResilientSender(client,
+		mev.WithMaxRetries(0),
+		mev.WithCircuitBreaker(2, 0, 50*time.Millisecond),
+		mev.WithRateLimiter(rate.NewLimiter(10, time.Second)),
+	)
+
+	for range 2 {
+		_, err = sender.SimulateBundle(context.Background(), 1, newSignedTx(t, 0))
+		require.ErrorIs(t, err, mev.ErrBuilderUnavailable)
+	}
+	require.Equal(t, mev.CircuitOpen, sender.Health().State)
+	require.Equal(t, 2, sender.Health().ConsecutiveFailures)
+
+	_, err = sender.SimulateBundle(context.Background(), 1, newSignedTx(t, 0))
+	require.ErrorIs(t, err, mev.ErrCircuitOpen)
+	require.Len(t, relay.RequestsFor(mev.EthCallBundleMethod), 2)
+
+	b, err := mev.NewBroadcaster([]mev.IBundleSender{sender}, nil)
+	require.NoError(t, err)
+	results := b.SimulateBundle(context.Background(), 1)
+	require.ErrorIs(t, results[mev.BundleSenderTypeBeaver.String()].Err, mev.ErrCircuitOpen)
+
+	// the probe fails and reopens the circuit, the next one closes it
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, mev.CircuitHalfOpen, sender.Health().State)
+	_, err = sender.SimulateBundle(context.Background(), 1, newSignedTx(t, 0))
+	require.ErrorIs(t, err, mev.ErrBuilderUnavailable)
+	require.Equal(t, mev.CircuitOpen, sender.Health().State)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = sender.SimulateBundle(context.Background(), 1, newSignedTx(t, 0))
+	require.NoError(t, err)
+	require.Equal(t, mev.CircuitClosed, sender.Health().State)
+}
+
+func TestResilientSender_SlowCall(t *testing.T) {
+	relay := mevtest.NewRelay(mev.BundleSenderTypeFlashbot)
+	defer relay.Close()
+	relay.Script(mev.ETHCancelBundleMethod, mevtest.Response{Delay: 20 * time.Millisecond})
+
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	sender := mev.NewResilientSender(client, mev.WithCircuitBreaker(1, 10*time.Millisecond, time.Minute))
+
+	require.NoError(t, sender.CancelBundle(context.Background(), "uuid"))
+	require.Equal(t, mev.CircuitOpen, sender.Health().State)
+	require.ErrorIs(t, sender.CancelBundle(context.Background(), "uuid"), mev.ErrCircuitOpen)
+}
@@ -0,0 +1,111 @@
+package mev
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// validateBundleTx checks the txs that builders would reject before sending them.
+func validateBundleTx(tx *types.Transaction) error {
+	if err := validateBlobSidecar(tx); err != nil {
+		return err
+	}
+	if tx.Type() == types.SetCodeTxType && len(tx.SetCodeAuthorizations()) == 0 {
+		return fmt.Errorf("%w: %s", ErrEmptyAuthList, tx.Hash())
+	}
+
+	return nil
+}
+
+// AttachAuthorizations appends auths to the first bundle tx, converted to a set-code tx and re-signed with key.
+// key must belong to the sender of the first tx, the other txs are returned unchanged.
+func AttachAuthorizations(
+	key *ecdsa.PrivateKey, txs []*types.Transaction, auths ...types.SetCodeAuthorization,
+) ([]*types.Transaction, error) {
+	if len(txs) == 0 || len(auths) == 0 {
+		return txs, nil
+	}
+
+	first := txs[0]
+	signer := types.LatestSignerForChainID(first.ChainId())
+	from, err := types.Sender(signer, first)
+	if err != nil {
+		return nil, fmt.Errorf("get sender of tx %s: %w", first.Hash(), err)
+	}
+	if crypto.PubkeyToAddress(key.PublicKey) != from {
+		return nil, fmt.Errorf("key does not belong to %s", from)
+	}
+	if first.To() == nil {
+		return nil, fmt.Errorf("set code tx cannot create a contract: %s", first.Hash())
+	}
+	switch first.Type() {
+	case types.DynamicFeeTxType, types.SetCodeTxType:
+	default:
+		return nil, fmt.Errorf("%w: can not attach authorizations to tx type %d", ErrMethodNotSupport, first.Type())
+	}
+
+	inner := &types.SetCodeTx{
+		ChainID:    uint256.MustFromBig(first.ChainId()),
+		Nonce:      first.Nonce(),
+		GasTipCap:  uint256.MustFromBig(first.GasTipCap()),
+		GasFeeCap:  uint256.MustFromBig(first.GasFeeCap()),
+		Gas:        first.Gas(),
+		To:         *first.To(),
+		Value:      uint256.MustFromBig(first.Value()),
+		Data:       first.Data(),
+		AccessList: first.AccessList(),
+		AuthList:   append(first.SetCodeAuthorizations(), auths...),
+	}
+	signed, err := types.SignNewTx(key, signer, inner)
+	if err != nil {
+		return nil, fmt.Errorf("sign set code tx: %w", err)
+	}
+
+	out := make([]*types.Transaction, len(txs))
+	out[0] = signed
+	copy(out[1:], txs[1:])
+
+	return out, nil
+}
+
+// CheckAuthorizationNonces returns an ErrAuthNonceConflict error when an authorization of the bundle
+// does not match the nonce of its authority at that point of the bundle, or when a later tx of a delegated
+// authority does not follow the nonce consumed by the authorization.
+// Invalid authorizations are skipped by the EVM without reverting, so the delegation would silently be lost.
+func CheckAuthorizationNonces(txs ...*types.Transaction) error {
+	// next nonce of the senders and authorities seen so far
+	next := make(map[common.Address]uint64)
+	delegated := make(map[common.Address]bool)
+	for _, tx := range txs {
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return fmt.Errorf("get sender of tx %s: %w", tx.Hash(), err)
+		}
+		if nonce, ok := next[from]; ok && delegated[from] && tx.Nonce() != nonce {
+			return fmt.Errorf("%w: tx %s of %s has nonce %d, expected %d",
+				ErrAuthNonceConflict, tx.Hash(), from, tx.Nonce(), nonce)
+		}
+		// the sender nonce is incremented before the authorizations are applied
+		next[from] = tx.Nonce() + 1
+
+		for _, auth := range tx.SetCodeAuthorizations() {
+			authority, err := auth.Authority()
+			if err != nil {
+				return fmt.Errorf("recover authority in tx %s: %w", tx.Hash(), err)
+			}
+			if nonce, ok := next[authority]; ok && auth.Nonce != nonce {
+				return fmt.Errorf("%w: authorization of %s in tx %s has nonce %d, expected %d",
+					ErrAuthNonceConflict, authority, tx.Hash(), auth.Nonce, nonce)
+			}
+			next[authority] = auth.Nonce + 1
+			delegated[authority] = true
+		}
+	}
+
+	return nil
+}
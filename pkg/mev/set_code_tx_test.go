@@ -0,0 +1,123 @@
+package mev_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/KyberNetwork/tradinglib/pkg/mev/mevtest"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachAuthorizations(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	delegate := common.HexToAddress("0x63c0c19a282a1b52b07dd5a65b58948a07dae32b")
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	first, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     3,
+		To:        &sender,
+		Gas:       100000,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(2),
+		Data:      []byte{0x01},
+	})
+	require.NoError(t, err)
+	// the sender delegates its own account, its nonce is already consumed by the tx
+	auth, err := types.SignSetCode(key, types.SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: delegate,
+		Nonce:   4,
+	})
+	require.NoError(t, err)
+	second := newSignedTx(t, 0)
+
+	txs, err := mev.AttachAuthorizations(key, []*types.Transaction{first, second}, auth)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	require.Equal(t, uint8(types.SetCodeTxType), txs[0].Type())
+	require.Equal(t, []types.SetCodeAuthorization{auth}, txs[0].SetCodeAuthorizations())
+	require.Equal(t, first.Nonce(), txs[0].Nonce())
+	require.Equal(t, first.Data(), txs[0].Data())
+	require.Equal(t, second, txs[1])
+	from, err := types.Sender(signer, txs[0])
+	require.NoError(t, err)
+	require.Equal(t, sender, from)
+	require.NoError(t, mev.CheckAuthorizationNonces(txs...))
+
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = mev.AttachAuthorizations(other, []*types.Transaction{first}, auth)
+	require.Error(t, err)
+
+	// set code txs are sent like any other tx
+	relay := mevtest.NewRelay(mev.BundleSenderTypeFlashbot)
+	defer relay.Close()
+	client, err := mev.NewClient(relay.Client(), relay.URL(), nil, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	_, err = client.SendBundle(context.Background(), nil, 100, txs...)
+	require.NoError(t, err)
+	var params []mev.SendBundleParams
+	require.NoError(t, json.Unmarshal(relay.RequestsFor(mev.ETHSendBundleMethod)[0].Params, &params))
+	var sent types.Transaction
+	require.NoError(t, sent.UnmarshalBinary(hexutil.MustDecode(params[0].Txs[0])))
+	require.Equal(t, txs[0].Hash(), sent.Hash())
+
+	empty, err := types.SignNewTx(key, signer, &types.SetCodeTx{ChainID: uint256.NewInt(1), To: sender})
+	require.NoError(t, err)
+	_, err = client.SendBundle(context.Background(), nil, 100, empty)
+	require.ErrorIs(t, err, mev.ErrEmptyAuthList)
+}
+
+func TestCheckAuthorizationNonces(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	authorityKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	authority := crypto.PubkeyToAddress(authorityKey.PublicKey)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	setCode := func(authNonce uint64) *types.Transaction {
+		auth, err := types.SignSetCode(authorityKey, types.SetCodeAuthorization{
+			ChainID: *uint256.NewInt(1),
+			Address: common.HexToAddress("0x01"),
+			Nonce:   authNonce,
+		})
+		require.NoError(t, err)
+		tx, err := types.SignNewTx(key, signer, &types.SetCodeTx{
+			ChainID:  uint256.NewInt(1),
+			To:       authority,
+			Gas:      100000,
+			AuthList: []types.SetCodeAuthorization{auth},
+		})
+		require.NoError(t, err)
+		return tx
+	}
+	fromAuthority := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignNewTx(authorityKey, signer, &types.DynamicFeeTx{
+			ChainID: big.NewInt(1),
+			Nonce:   nonce,
+			To:      &authority,
+			Gas:     21000,
+		})
+		require.NoError(t, err)
+		return tx
+	}
+
+	require.NoError(t, mev.CheckAuthorizationNonces(setCode(7), fromAuthority(8)))
+	// the authorization consumes nonce 7, a later tx reusing it is invalid
+	require.ErrorIs(t, mev.CheckAuthorizationNonces(setCode(7), fromAuthority(7)), mev.ErrAuthNonceConflict)
+	// the authority sent nonce 7 earlier in the bundle, the authorization must use 8
+	require.ErrorIs(t, mev.CheckAuthorizationNonces(fromAuthority(7), setCode(7)), mev.ErrAuthNonceConflict)
+	require.NoError(t, mev.CheckAuthorizationNonces(fromAuthority(7), setCode(8), fromAuthority(9)))
+}
@@ -0,0 +1,109 @@
+package mev
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/KyberNetwork/tradinglib/pkg/ctxkey"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const maxSignedBodySize = 10 << 20
+
+type signerCtxKey struct{}
+
+// ParseSignatureHeader splits a Flashbots-style "address:signature" header.
+func ParseSignatureHeader(header string) (common.Address, []byte, error) {
+	if header == "" {
+		return common.Address{}, nil, ErrMissingSignature
+	}
+	addr, sigHex, ok := strings.Cut(header, ":")
+	if !ok || !common.IsHexAddress(addr) {
+		return common.Address{}, nil, fmt.Errorf("%w: invalid address", ErrMalformedSignature)
+	}
+	sig, err := hexutil.Decode(sigHex)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("%w: %w", ErrMalformedSignature, err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, nil, fmt.Errorf("%w: invalid length %d", ErrMalformedSignature, len(sig))
+	}
+
+	return common.HexToAddress(addr), sig, nil
+}
+
+// VerifyRequestSignature checks a header produced by requestSignature against the request body
+// and returns the authenticated address.
+func VerifyRequestSignature(header string, body []byte) (common.Address, error) {
+	claimed, sig, err := ParseSignatureHeader(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	// some signers (e.g. ethers.js) encode V as 27/28 instead of 0/1
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	hashed := crypto.Keccak256Hash(body).Hex()
+	pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(hashed)), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", ErrMalformedSignature, err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != claimed {
+		return common.Address{}, fmt.Errorf("%w: claimed %s, recovered %s", ErrSignatureMismatch, claimed, signer)
+	}
+
+	return claimed, nil
+}
+
+// SignerFromContext returns the address authenticated by SignatureMiddleware.
+func SignerFromContext(ctx context.Context) (common.Address, bool) {
+	return ctxkey.GetCheck[common.Address](ctx, signerCtxKey{})
+}
+
+// SignatureMiddleware authenticates requests signed in the header headerName
+// (e.g. XFlashbotSignatureHeader) and stores the signer in the request context.
+func SignatureMiddleware(headerName string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBodySize))
+		if err != nil {
+			writeSignatureError(w, http.StatusBadRequest, fmt.Errorf("read body: %w", err))
+			return
+		}
+
+		signer, err := VerifyRequestSignature(r.Header.Get(headerName), body)
+		if err != nil {
+			writeSignatureError(w, http.StatusForbidden, err)
+			return
+		}
+
+		r = r.WithContext(ctxkey.NewSetter(r.Context()).Set(signerCtxKey{}, signer).Ctx())
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeSignatureError(w http.ResponseWriter, status int, err error) {
+	body, _ := json.Marshal(struct {
+		Jsonrpc string        `json:"jsonrpc"`
+		Error   ErrorResponse `json:"error"`
+	}{
+		Jsonrpc: JSONRPC2,
+		Error: ErrorResponse{
+			Code:     -32600,
+			Messange: err.Error(),
+		},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,56 @@
+package mev_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KyberNetwork/tradinglib/pkg/mev"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureMiddleware(t *testing.T) {
+	var signer common.Address
+	handler := mev.SignatureMiddleware(mev.XFlashbotSignatureHeader,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var ok bool
+			signer, ok = mev.SignerFromContext(r.Context())
+			require.True(t, ok)
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x01"}}`))
+		}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	client, err := mev.NewClient(server.Client(), server.URL, key, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	_, err = client.SendBundle(context.Background(), nil, 1, newSignedTx(t, 0))
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer)
+
+	unsigned, err := mev.NewClient(server.Client(), server.URL, nil, mev.BundleSenderTypeFlashbot, false)
+	require.NoError(t, err)
+	_, err = unsigned.SendBundle(context.Background(), nil, 1, newSignedTx(t, 0))
+	require.ErrorContains(t, err, "403")
+}
+
+func TestVerifyRequestSignature(t *testing.T) {
+	other := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	validSig := "0x" + common.Bytes2Hex(make([]byte, crypto.SignatureLength))
+
+	_, err := mev.VerifyRequestSignature("", []byte("{}"))
+	require.ErrorIs(t, err, mev.ErrMissingSignature)
+
+	_, err = mev.VerifyRequestSignature("not-an-address:0x00", []byte("{}"))
+	require.ErrorIs(t, err, mev.ErrMalformedSignature)
+
+	_, err = mev.VerifyRequestSignature(other.Hex()+":0x1234", []byte("{}"))
+	require.ErrorIs(t, err, mev.ErrMalformedSignature)
+
+	_, err = mev.VerifyRequestSignature(other.Hex()+":"+validSig, []byte("{}"))
+	require.Error(t, err)
+}
@@ -17,3 +17,15 @@ type ExecuteBundle struct {
 	ArbitrageType string         `db:"arb_type"`
 	Operation     string         `db:"operation"`
 }
+
+// TrackedExecuteBundle is an ExecuteBundle with its inclusion outcome, filled once the bundle has been tracked.
+// It is stored apart from ExecuteBundle so the execute bundle table keeps its columns.
+type TrackedExecuteBundle struct {
+	ExecuteBundle
+	// MaxBlockNumber is the last target block, zero when only BlockNumber is targeted.
+	MaxBlockNumber    uint64 `db:"max_block_number"`
+	Status            string `db:"status"`
+	LandedBlockNumber uint64 `db:"landed_block_number"`
+	FeeRecipient      string `db:"fee_recipient"`
+	Position          uint   `db:"position"`
+}